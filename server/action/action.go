@@ -3,7 +3,9 @@ package action
 import (
 	"log"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
 	"thereaalm/types"
+	"thereaalm/weather"
 )
 
 type Action struct {
@@ -32,6 +34,9 @@ func (a *Action) GetWeighting() float64 {return a.Weighting}
 func (a *Action) GetTarget() interfaces.IEntity {return a.Target}
 func (a *Action) GetActor() interfaces.IEntity {return a.Actor}
 func (a *Action) SetTarget(newTarget interfaces.IEntity) {
+	if a.Target != newTarget {
+		a.ReleaseWorkSlot()
+	}
 	a.Target = newTarget
 }
 
@@ -47,61 +52,65 @@ func (a *Action) SetFallbackTargetSpec(fallbackTargetSpec *types.TargetSpec) {
     a.FallbackTargetSpec = fallbackTargetSpec
 }
 
-// utility function to move to a target
-func (a *Action) CanMoveToTargetEntity(target interfaces.IEntity) bool {
+// GetPreconditions is what has to be true before the action can run, so the
+// planner knows what to do first. Most actions don't need anything.
+func (a *Action) GetPreconditions() types.WorldState { return nil }
+
+// GetEffects is what the planner can count on once the action's done
+func (a *Action) GetEffects() types.WorldState { return nil }
+
+// GetWeatherEffects returns the effects of the weather where the actor is standing
+func (a *Action) GetWeatherEffects() weather.Effects {
 	zone := a.Actor.GetZone()
-	_, _, found := zone.TryGetEmptyTileNextToTargetEntity(target)
-	return found
+	if zone == nil {
+		return weather.NoEffects
+	}
+	return zone.GetWeather().Effects()
 }
 
-func (a *Action) TryMoveToTargetEntity(target interfaces.IEntity) bool {
-	// check if already next to target
-	if a.Actor.IsNextToTargetEntity(target) {
-		// ensure we're facing the target
-		a.Actor.SetDirectionToTargetEntity(target)
-		return true
-	} else {
-		// check spatial map for a valid position next to the target
-		zone := a.Actor.GetZone()
-		nx, ny, found := zone.TryGetEmptyTileNextToTargetEntity(target)
-		if !found {
-			return false
+// GetTravelGoal is where the actor walks to before the action starts: the
+// slot it booked at the target, anywhere next to the target's footprint if it
+// didn't need one, or nowhere if there isn't a target
+func (a *Action) GetTravelGoal() (pathfinding.Goal, bool) {
+	if a.Target == nil {
+		return pathfinding.Goal{}, false
+	}
+	if slots, ok := a.Target.(interfaces.IWorkSlots); ok {
+		if tile, held := slots.GetClaimedSlot(a.Actor.GetUUID()); held {
+			return pathfinding.Goal{Target: pathfinding.PointRect(tile)}, true
 		}
+	}
+	return pathfinding.Goal{
+		Target:   a.Target.GetBounds(),
+		Adjacent: true,
+	}, true
+}
 
-		// move to target
-		a.Actor.SetPosition(nx, ny)
-
-		// ensure we're facing the target
-		a.Actor.SetDirectionToTargetEntity(target)
-
+// CanMoveToTargetEntity is true if the actor is next to target or there's a
+// path to a free tile next to it. A search that runs out of this tick's
+// budget counts as no path, the action can be picked next time round.
+func (a *Action) CanMoveToTargetEntity(target interfaces.IEntity) bool {
+	if a.Actor.IsNextToTargetEntity(target) {
 		return true
 	}
+
+	zone := a.Actor.GetZone()
+	ax, ay := a.Actor.GetPosition()
+	if !zone.IsReachable(ax, ay, target) {
+		return false // walled off, don't waste a search on it
+	}
+	_, result := zone.FindPathToEntity(ax, ay, target)
+	return result == pathfinding.Found
 }
 
+// CanMoveToTargetPosition is true if there's a path to the position
 func (a *Action) CanMoveToTargetPosition(x, y int) bool {
 	zone := a.Actor.GetZone()
-	return zone.IsPositionAvailable(x,y)
+	ax, ay := a.Actor.GetPosition()
+	_, result := zone.FindPath(ax, ay, positionGoal(x, y))
+	return result == pathfinding.Found
 }
 
-func (a *Action) TryMoveToTargetPosition(x, y int) bool {
-	currX, currY := a.Actor.GetPosition()
-
-	// check if already at the target position
-	if currX == x && currY == y {
-		return true
-	} else {
-		// check spatial map that target is valid position
-		zone := a.Actor.GetZone()
-		if !zone.IsPositionAvailable(x, y) {
-			return false
-		}
-
-		// ensure we're facing the target position
-		a.Actor.SetDirectionToTargetPosition(x, y)
-
-		// move to target
-		a.Actor.SetPosition(x, y)
-
-		return true
-	}
-}
\ No newline at end of file
+func positionGoal(x, y int) pathfinding.Goal {
+	return pathfinding.Goal{Target: pathfinding.PointRect(pathfinding.Point{X: x, Y: y})}
+}
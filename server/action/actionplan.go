@@ -1,25 +1,53 @@
 package action
 
 import (
-	"math/rand"
+	"math"
 	"thereaalm/action/actiontargeting"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
 )
 
 type ActionPlan struct {
     Actions []interfaces.IAction
     CurrentAction interfaces.IAction
+    Travelling bool // The actor is walking to CurrentAction, it hasn't started yet
+    travelGoal pathfinding.Goal
+
+    SelectionMode SelectionMode // How to pick between scored actions, softmax if unset
+    Temperature float64 // Softmax temperature, defaultTemperature if unset
+    scores map[interfaces.IAction]ActionScore // From the last selection, for reporting
+
+    Goals []Goal
+    goal string // Goal the planned steps are working towards
+    plannedSteps []interfaces.IAction // Still to do once CurrentAction's finished
 }
 
 func (a *ActionPlan) AddActionToPlan(action interfaces.IAction) {
     a.Actions = append(a.Actions, action)
 }
 
+// AddGoalToPlan gives the actor something to work towards, the planner chains
+// the plan's actions together to get there
+func (a *ActionPlan) AddGoalToPlan(goal Goal) {
+    a.Goals = append(a.Goals, goal)
+}
+
+// InterruptWith drops whatever the actor is doing and starts action straight
+// away (walking to it first if need be). The action isn't added to the plan
+// so it only runs the once.
+func (a *ActionPlan) InterruptWith(action interfaces.IAction) {
+    a.beginAction(action)
+}
+
 func (a *ActionPlan) ProcessActions(dt_s float64) {
 	if a.CurrentAction == nil {
         a.SelectNextAction()
         return // Early return if no action to process
     }
+    if a.Travelling {
+        a.travel(dt_s)
+        return
+    }
     actor := a.CurrentAction.GetActor()
     scaledDt := dt_s
     if consumer, ok := actor.(interfaces.IBuffConsumer); ok {
@@ -27,8 +55,26 @@ func (a *ActionPlan) ProcessActions(dt_s float64) {
     }
     actionComplete := a.CurrentAction.Update(scaledDt)
     if actionComplete {
-        a.CurrentAction = nil
+        a.DropCurrentAction()
+    }
+}
+
+// DropCurrentAction abandons the current action (finished or not) and gives
+// back any slot it booked at its target
+func (a *ActionPlan) DropCurrentAction() {
+    if a.CurrentAction != nil {
+        a.CurrentAction.ReleaseWorkSlot()
     }
+    a.CurrentAction = nil
+    a.Travelling = false
+}
+
+// candidate is something SelectNextAction could start: a single action, or
+// the first of a chain of them working towards a goal
+type candidate struct {
+	steps []interfaces.IAction
+	goal  string
+	score float64
 }
 
 // SelectNextAction will only select actions that can be executed.
@@ -40,53 +86,146 @@ func (a *ActionPlan) SelectNextAction() {
 		return
 	}
 
-	// Calculate total weighting to normalize probabilities, considering only executable actions.
-	var totalWeight float64
-	executableActions := []interfaces.IAction{}
+	// carry on with a chain of actions if one's under way
+	if a.continuePlannedSteps() {
+		return
+	}
+
+	// Score actions that can be executed.
+	a.scores = make(map[interfaces.IAction]ActionScore, len(a.Actions))
+	candidates := []candidate{}
+	plannable := []interfaces.IAction{}
 
 	// Filter out actions that cannot be executed.
 	for _, action := range a.Actions {
-		actionCurrentTarget := action.GetTarget()
-
-		// first establish fallbacks if required/possible
-		if 	actionCurrentTarget == nil || 
-			!action.IsValidTarget(actionCurrentTarget) {
-			
-			newTarget := actiontargeting.ResolveFallbackTarget(action)
-			action.SetTarget(newTarget)
-		}
+		resolveTarget(action)
 
-		// see if actor and target is valid
-		if !action.IsValidActor(action.GetActor()) || 
-			!action.IsValidTarget(action.GetTarget()) {
+		// see if target is valid
+		if !action.IsValidTarget(action.GetTarget()) {
+			continue
+		}
 
+		// a valid target is enough for the planner, it works out how to
+		// make the actor valid
+		plannable = append(plannable, action)
+		if !action.IsValidActor(action.GetActor()) {
 			continue
 		}
 
-		// add to possible executable actions (if possible)
-		if action.GetWeighting() > 0 {
-			executableActions = append(executableActions, action)
-			totalWeight += action.GetWeighting()
+		// add to possible executable actions (if they're worth doing at all)
+		score := ScoreAction(action)
+		a.scores[action] = score
+		if score.Score > 0 {
+			candidates = append(candidates, candidate{
+				steps: []interfaces.IAction{action},
+				score: score.Score,
+			})
+		}
+	}
+
+	// add plans for any goals we can see a way to
+	if len(a.Goals) > 0 && len(plannable) > 0 {
+		actor := plannable[0].GetActor()
+		state := CurrentWorldState(actor)
+		for _, goal := range a.Goals {
+			steps := Plan(state, goal.Conditions, plannable)
+			if len(steps) == 0 || !steps[0].IsValidActor(actor) {
+				continue
+			}
+
+			// the goal's worth however much we'd want the action that finishes it
+			score := goal.Weighting * ScoreAction(steps[len(steps)-1]).Score
+			if score > 0 {
+				candidates = append(candidates, candidate{steps: steps, goal: goal.Name, score: score})
+			}
 		}
 	}
 
 	// If no executable actions, return.
-	if len(executableActions) == 0 {
+	if len(candidates) == 0 {
 		// log.Println("No executable actions available.")
 		return
 	}
 
-	// Choose a random executable action based on weighting.
-	randomWeight := rand.Float64() * totalWeight
+	chosen := a.chooseCandidate(candidates)
+	a.goal = chosen.goal
+	a.plannedSteps = chosen.steps[1:]
+	a.beginAction(chosen.steps[0])
+}
+
+// continuePlannedSteps starts the next step towards the current goal. If it
+// can't be done any more the rest of the chain is dropped.
+func (a *ActionPlan) continuePlannedSteps() bool {
+	if len(a.plannedSteps) == 0 {
+		a.goal = ""
+		return false
+	}
+
+	next := a.plannedSteps[0]
+	a.plannedSteps = a.plannedSteps[1:]
+
+	resolveTarget(next)
+	if !next.IsValidActor(next.GetActor()) || !next.IsValidTarget(next.GetTarget()) {
+		a.goal = ""
+		a.plannedSteps = nil
+		return false
+	}
+
+	a.beginAction(next)
+	return true
+}
+
+// resolveTarget falls back to a new target if the action hasn't got a valid one
+func resolveTarget(action interfaces.IAction) {
+	actionCurrentTarget := action.GetTarget()
+
+	// first establish fallbacks if required/possible
+	// - targets that have left the world aren't coming back
+	if 	actionCurrentTarget == nil || 
+		actionCurrentTarget.GetZone() == nil ||
+		!action.IsValidTarget(actionCurrentTarget) {
+		
+		newTarget := actiontargeting.ResolveFallbackTarget(action)
+		action.SetTarget(newTarget)
+	}
+}
+
+// chooseCandidate picks one of the scored candidates by the plan's SelectionMode
+func (a *ActionPlan) chooseCandidate(candidates []candidate) candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.score > best.score {
+			best = c
+		}
+	}
+	if a.selectionMode() == SelectHighest {
+		return best
+	}
+
+	temperature := a.Temperature
+	if temperature <= 0 {
+		temperature = defaultTemperature
+	}
+
+	// softmax, shifted by the best score so exp can't overflow
+	weights := make([]float64, len(candidates))
+	var totalWeight float64
+	for i, c := range candidates {
+		weights[i] = math.Exp((c.score - best.score) / temperature)
+		totalWeight += weights[i]
+	}
+
+	// - all actions in a plan share the same actor so we roll on its random stream
+	rng := candidates[0].steps[0].GetActor().GetRand()
+	randomWeight := rng.Float64() * totalWeight
 	var cumulativeWeight float64
-	for _, action := range executableActions {
-		cumulativeWeight += action.GetWeighting()
+	for i, c := range candidates {
+		cumulativeWeight += weights[i]
 		if cumulativeWeight >= randomWeight {
-			a.CurrentAction = action
-			a.CurrentAction.Start()
-			return
+			return c
 		}
 	}
+	return best
 }
 
 
@@ -95,6 +234,10 @@ func (a *ActionPlan) SelectNextAction() {
 type ActionPlanReporting struct {
 	Actions       []ActionReporting `json:"actions"`
 	CurrentAction *ActionReporting  `json:"currentAction,omitempty"`
+	Travelling    bool              `json:"travelling"`
+	SelectionMode SelectionMode     `json:"selectionMode"`
+	Goal          string            `json:"goal,omitempty"`         // What the planned steps are working towards
+	PlannedSteps  []ActionReporting `json:"plannedSteps,omitempty"` // Still to do after the current action
 }
 
 type ActionReporting struct {
@@ -104,46 +247,65 @@ type ActionReporting struct {
 	TargetType string `json:"targetType,omitempty"`
 	TargetID   string `json:"targetId,omitempty"`
 	Weighting float64 `json:"weighting"`
+	Score *ActionScore `json:"score,omitempty"` // Breakdown from the last selection, if it was scored
 }
 
 // ToReporting converts ActionPlan to a cycle-free reporting version
 func (a *ActionPlan) ToReporting() ActionPlanReporting {
 	actions := make([]ActionReporting, len(a.Actions))
 	for i, action := range a.Actions {
-		var targetType, targetID string
-		if action.GetTarget() != nil {
-			targetType = action.GetTarget().GetType()
-			targetID = action.GetTarget().GetUUID().String()
-		}
-		actions[i] = ActionReporting{
-			Type:       action.GetType(),
-			ActorType:  action.GetActor().GetType(),
-			ActorID:    action.GetActor().GetUUID().String(),
-			TargetType: targetType,
-			TargetID:   targetID,
-			Weighting: action.GetWeighting(),
-		}
+		actions[i] = a.reportAction(action)
 	}
 
 	var current *ActionReporting
 	if a.CurrentAction != nil {
-		var targetType, targetID string
-		if a.CurrentAction.GetTarget() != nil {
-			targetType = a.CurrentAction.GetTarget().GetType()
-			targetID = a.CurrentAction.GetTarget().GetUUID().String()
-		}
-		current = &ActionReporting{
-			Type:       a.CurrentAction.GetType(),
-			ActorType:  a.CurrentAction.GetActor().GetType(),
-			ActorID:    a.CurrentAction.GetActor().GetUUID().String(),
-			TargetType: targetType,
-			TargetID:   targetID,
-			Weighting: a.CurrentAction.GetWeighting(),
-		}
+		report := a.reportAction(a.CurrentAction)
+		current = &report
+	}
+
+	var plannedSteps []ActionReporting
+	for _, step := range a.plannedSteps {
+		plannedSteps = append(plannedSteps, a.reportAction(step))
 	}
 
 	return ActionPlanReporting{
 		Actions:       actions,
 		CurrentAction: current,
+		Travelling:    a.Travelling,
+		SelectionMode: a.selectionMode(),
+		Goal:          a.goal,
+		PlannedSteps:  plannedSteps,
+	}
+}
+
+func (a *ActionPlan) reportAction(action interfaces.IAction) ActionReporting {
+	var targetType, targetID string
+	if action.GetTarget() != nil {
+		targetType = action.GetTarget().GetType()
+		targetID = action.GetTarget().GetUUID().String()
+	}
+	return ActionReporting{
+		Type:       action.GetType(),
+		ActorType:  action.GetActor().GetType(),
+		ActorID:    action.GetActor().GetUUID().String(),
+		TargetType: targetType,
+		TargetID:   targetID,
+		Weighting: action.GetWeighting(),
+		Score: a.lastScore(action),
+	}
+}
+
+func (a *ActionPlan) lastScore(action interfaces.IAction) *ActionScore {
+	score, ok := a.scores[action]
+	if !ok {
+		return nil
+	}
+	return &score
+}
+
+func (a *ActionPlan) selectionMode() SelectionMode {
+	if a.SelectionMode == "" {
+		return SelectSoftmax
 	}
+	return a.SelectionMode
 }
\ No newline at end of file
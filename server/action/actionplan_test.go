@@ -0,0 +1,94 @@
+package action
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"thereaalm/interfaces"
+)
+
+// randActor is just enough of an actor to roll on
+type randActor struct {
+	interfaces.IEntity
+	rng *rand.Rand
+}
+
+func (r *randActor) GetRand() *rand.Rand { return r.rng }
+
+// scoredCandidates are single actions for one actor with the given scores
+func scoredCandidates(seed int64, scores ...float64) []candidate {
+	actor := &randActor{rng: rand.New(rand.NewSource(seed))}
+	candidates := make([]candidate, len(scores))
+	for i, score := range scores {
+		candidates[i] = candidate{steps: []interfaces.IAction{&Action{Actor: actor}}, score: score}
+	}
+	return candidates
+}
+
+// pickCounts chooses rolls times and counts how often each candidate won
+func pickCounts(plan *ActionPlan, candidates []candidate, rolls int) []int {
+	counts := make([]int, len(candidates))
+	for i := 0; i < rolls; i++ {
+		chosen := plan.chooseCandidate(candidates)
+		for j := range candidates {
+			if candidates[j].steps[0] == chosen.steps[0] {
+				counts[j]++
+			}
+		}
+	}
+	return counts
+}
+
+func TestChooseCandidate(t *testing.T) {
+	const rolls = 20000
+	scores := []float64{0.2, 0.5, 0.4}
+
+	// softmax probabilities for scores at temperature
+	softmax := func(temperature float64) []float64 {
+		probs := make([]float64, len(scores))
+		total := 0.0
+		for i, s := range scores {
+			probs[i] = math.Exp(s / temperature)
+			total += probs[i]
+		}
+		for i := range probs {
+			probs[i] /= total
+		}
+		return probs
+	}
+
+	tests := []struct {
+		name string
+		plan ActionPlan
+		want []float64
+	}{
+		{"highest", ActionPlan{SelectionMode: SelectHighest}, []float64{0, 1, 0}},
+		{"highest ignores temperature", ActionPlan{SelectionMode: SelectHighest, Temperature: 10}, []float64{0, 1, 0}},
+		{"softmax by default", ActionPlan{}, softmax(defaultTemperature)},
+		{"softmax hot", ActionPlan{SelectionMode: SelectSoftmax, Temperature: 1}, softmax(1)},
+		{"softmax cold", ActionPlan{SelectionMode: SelectSoftmax, Temperature: 0.01}, softmax(0.01)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := pickCounts(&tt.plan, scoredCandidates(1, scores...), rolls)
+			for i, want := range tt.want {
+				if got := float64(counts[i]) / rolls; math.Abs(got-want) > 0.02 {
+					t.Fatalf("candidate %d chosen %.3f of the time, want %.3f (%v)", i, got, want, counts)
+				}
+			}
+		})
+	}
+}
+
+// TestChooseCandidateIsSeeded makes sure softmax only rolls on the actor's stream
+func TestChooseCandidateIsSeeded(t *testing.T) {
+	var plan ActionPlan
+	first := pickCounts(&plan, scoredCandidates(7, 0.3, 0.35, 0.3), 200)
+	second := pickCounts(&plan, scoredCandidates(7, 0.3, 0.35, 0.3), 200)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("same seed chose %v then %v", first, second)
+		}
+	}
+}
@@ -2,11 +2,9 @@ package actiontargeting
 
 import (
 	"math"
-	"math/rand"
 	"thereaalm/interfaces"
 	"thereaalm/stattypes"
 	"thereaalm/types"
-	"thereaalm/utils"
 )
 
 type FallbackCriteria string
@@ -29,8 +27,19 @@ var FallbackHandlers = map[FallbackCriteria]func(a interfaces.IAction) interface
 
 // --- Shared Helpers ---
 
+// candidatesFor returns every entity of the spec's target type in the actor's
+// zone, or just the ones it can see if the spec asks for that
+func candidatesFor(a interfaces.IAction) []interfaces.IEntity {
+	spec := a.GetFallbackTargetSpec()
+	zone := a.GetActor().GetZone()
+	if spec.PerceivedOnly {
+		return zone.FindPerceivedEntities(a.GetActor(), spec.TargetType)
+	}
+	return zone.GetEntitiesByType(spec.TargetType)
+}
+
 func filterValidCandidates(a interfaces.IAction) []interfaces.IEntity {
-	candidates := a.GetActor().GetZone().GetEntitiesByType(a.GetFallbackTargetSpec().TargetType)
+	candidates := candidatesFor(a)
 	valid := make([]interfaces.IEntity, 0, len(candidates))
 	for _, c := range candidates {
 		if c.GetUUID() != a.GetActor().GetUUID() && a.IsValidTarget(c) {
@@ -42,25 +51,37 @@ func filterValidCandidates(a interfaces.IAction) []interfaces.IEntity {
 
 // --- Fallback Implementations ---
 
+// fallbackNearestRange is how far (Manhattan) nearest will look for a target
+const fallbackNearestRange = 31
+
 func fallbackNearest(a interfaces.IAction) interfaces.IEntity {
-	valid := filterValidCandidates(a)
-	if len(valid) == 0 {
-		return nil
-	}
+	actor := a.GetActor()
+	ax, ay := actor.GetPosition()
 
-	ax, ay := a.GetActor().GetPosition()
-	var closest interfaces.IEntity
-	minDist := 32
+	spec := a.GetFallbackTargetSpec()
+	zone := actor.GetZone()
 
-	for _, candidate := range valid {
-		tx, ty := candidate.GetPosition()
-		dist := utils.Abs(ax - tx) + utils.Abs(ay - ty)
-		if dist < minDist {
-			minDist = dist
-			closest = candidate
-		}
+	maxRange := fallbackNearestRange
+	if spec.PerceivedOnly {
+		// vision is as the crow flies, this search is Manhattan
+		maxRange = min(maxRange, 2*zone.GetVisionRadius(actor))
+	}
+
+	nearest := zone.FindNearestEntities(ax, ay, spec.TargetType, 1, maxRange,
+		func(candidate interfaces.IEntity) bool {
+			if candidate.GetUUID() == actor.GetUUID() {
+				return false
+			}
+			// seeing it is much cheaper than IsValidTarget, which paths to it
+			if spec.PerceivedOnly && !zone.CanPerceive(actor, candidate) {
+				return false
+			}
+			return a.IsValidTarget(candidate)
+		})
+	if len(nearest) == 0 {
+		return nil
 	}
-	return closest
+	return nearest[0]
 }
 
 func fallbackLowestPulse(a interfaces.IAction) interfaces.IEntity {
@@ -106,8 +127,7 @@ func fallbackHasSpecificItem(a interfaces.IAction) interfaces.IEntity {
 		return nil
 	}
 
-	candidates := a.GetActor().GetZone().GetEntitiesByType(a.GetFallbackTargetSpec().TargetType)
-	for _, candidate := range candidates {
+	for _, candidate := range candidatesFor(a) {
 		if inventory, ok := candidate.(interfaces.IInventory); ok {
 			if inventory.GetItemQuantity(itemName) > 0 {
 				return candidate
@@ -122,7 +142,7 @@ func fallbackRandom(a interfaces.IAction) interfaces.IEntity {
 	if len(valid) == 0 {
 		return nil
 	}
-	return valid[rand.Intn(len(valid))]
+	return valid[a.GetActor().GetRand().Intn(len(valid))]
 }
 
 func ResolveFallbackTarget(a interfaces.IAction) interfaces.IEntity {
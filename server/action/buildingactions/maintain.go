@@ -91,6 +91,11 @@ func (a *MaintainAction) IsValidTarget(potentialTarget interfaces.IEntity) bool
 		return false
 	}
 
+	// is there room to work on it?
+	if a.IsFullyBooked(potentialTarget) {
+		return false
+	}
+
 	// can move to target?
 	if !a.CanMoveToTargetEntity(potentialTarget) {
 		return false
@@ -99,6 +104,22 @@ func (a *MaintainAction) IsValidTarget(potentialTarget interfaces.IEntity) bool
 	return true
 }
 
+// GetPreconditions needs a kekwood and an alphaslate to work with
+func (a *MaintainAction) GetPreconditions() types.WorldState {
+	return types.WorldState{
+		types.HasItem("kekwood"):    true,
+		types.HasItem("alphaslate"): true,
+	}
+}
+
+// GetEffects restores some pulse to whatever type of thing is being maintained
+func (a *MaintainAction) GetEffects() types.WorldState {
+	if a.Target == nil {
+		return nil
+	}
+	return types.WorldState{types.Restored(a.Target.GetType()): true}
+}
+
 func (a *MaintainAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 	itemHolder, _ := potentialActor.(interfaces.IInventory)
 	if itemHolder == nil {
@@ -117,8 +138,8 @@ func (a *MaintainAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 }
 
 func (a *MaintainAction) Start() {
-	// move to target
-	a.TryMoveToTargetEntity(a.Target)
+	// travel got us next to the target, face it
+	a.Actor.SetDirectionToTargetEntity(a.Target)
 }
 
 func (a *MaintainAction) Update(dt_s float64) bool {
@@ -132,9 +153,10 @@ func (a *MaintainAction) Update(dt_s float64) bool {
 		return true	// action is complete we have invalid actor or target
 	}
 
-	// reduce actor ecto and spark
-	actorStats.DeltaStat(stattypes.Ecto, -0.1*dt_s)
-	actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s)
+	// reduce actor ecto and spark, bad weather wears them down faster
+	drain := a.GetWeatherEffects().Drain
+	actorStats.DeltaStat(stattypes.Ecto, -0.1*dt_s*drain)
+	actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s*drain)
 
 	// see if 1 second has elapsed
 	a.Timer_s -= dt_s
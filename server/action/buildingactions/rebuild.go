@@ -85,19 +85,40 @@ func (a *RebuildAction) IsValidTarget(potentialTarget interfaces.IEntity) bool {
 		return false	// action is complete we have invalid actor or target
 	}
 
-	// can move to target?
-	if !a.CanMoveToTargetEntity(potentialTarget) {
+	// entity is ready to be rebuilt?
+	if !rebuildable.CanBeRebuilt() {
 		return false
 	}
 
-	// entity is ready to be rebuilt?
-	if !rebuildable.CanBeRebuilt() {
+	// is there room to work on it?
+	if a.IsFullyBooked(potentialTarget) {
+		return false
+	}
+
+	// can move to target?
+	if !a.CanMoveToTargetEntity(potentialTarget) {
 		return false
 	}
 
 	return true
 }
 
+// GetPreconditions needs a kekwood and an alphaslate to work with
+func (a *RebuildAction) GetPreconditions() types.WorldState {
+	return types.WorldState{
+		types.HasItem("kekwood"):    true,
+		types.HasItem("alphaslate"): true,
+	}
+}
+
+// GetEffects rebuilds whatever type of thing is being rebuilt
+func (a *RebuildAction) GetEffects() types.WorldState {
+	if a.Target == nil {
+		return nil
+	}
+	return types.WorldState{types.Rebuilt(a.Target.GetType()): true}
+}
+
 func (a *RebuildAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 	itemHolder, _ := potentialActor.(interfaces.IInventory);
 
@@ -118,8 +139,8 @@ func (a *RebuildAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 }
 
 func (a *RebuildAction) Start() {
-	// move to target
-	a.TryMoveToTargetEntity(a.Target)
+	// travel got us next to the target, face it
+	a.Actor.SetDirectionToTargetEntity(a.Target)
 }
 
 func (a *RebuildAction) Update(dt_s float64) bool {
@@ -133,9 +154,10 @@ func (a *RebuildAction) Update(dt_s float64) bool {
 		return true	// action is complete we have invalid actor or target
 	}
 
-	// reduce actor ecto and spark
-	actorStats.DeltaStat(stattypes.Ecto, -0.1*dt_s)
-	actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s)
+	// reduce actor ecto and spark, bad weather wears them down faster
+	drain := a.GetWeatherEffects().Drain
+	actorStats.DeltaStat(stattypes.Ecto, -0.1*dt_s*drain)
+	actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s*drain)
 
 	// see if 1 second has elapsed
 	a.Timer_s -= dt_s
@@ -62,11 +62,6 @@ func (a *AttackAction) IsValidTarget(potentialTarget interfaces.IEntity) bool {
 		return false	// action is complete we have invalid actor or target
 	}
 
-	// can we move to the target?
-	if !a.CanMoveToTargetEntity(potentialTarget) {
-		return false
-	}
-
 	// is target still alive?
 	if targetStats.GetStat(stattypes.Pulse) <= 0 {
 		return false
@@ -83,6 +78,16 @@ func (a *AttackAction) IsValidTarget(potentialTarget interfaces.IEntity) bool {
 		return false
 	}
 
+	// is there room to work on it?
+	if a.IsFullyBooked(potentialTarget) {
+		return false
+	}
+
+	// can we move to the target?
+	if !a.CanMoveToTargetEntity(potentialTarget) {
+		return false
+	}
+
 	return true
 }
 
@@ -109,8 +114,8 @@ func (a *AttackAction) Start() {
 		return 	
 	}
 
-	// move to target
-	a.TryMoveToTargetEntity(a.Target)
+	// travel got us next to the target, face it
+	a.Actor.SetDirectionToTargetEntity(a.Target)
 }
 
 
@@ -0,0 +1,158 @@
+package action
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/stattypes"
+	"thereaalm/utils"
+)
+
+// considerationRange is how far away (in tiles) a target has to be before
+// distance stops counting against it any more
+const considerationRange = 32.0
+
+// actionConsiderations is what each type of action weighs up when the plan is
+// choosing what to do next. Distance comes first for anything with a target,
+// the rest is what makes the action worth doing right now.
+var actionConsiderations = map[string][]consideration{
+	"forage": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"hungry", statLevel(stattypes.Pulse, stattypes.MaxPulse), Floor(0.3, InversePower(2))},
+		{"fomoberry", itemStock("fomoberry", 20), Floor(0.2, Inverse)},
+	},
+	"chop": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"kekwood", itemStock("kekwood", 10), Floor(0.2, Inverse)},
+		{"spark", espLevel(stattypes.Spark), Floor(0.5, Linear)},
+	},
+	"mine": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"alphaslate", itemStock("alphaslate", 10), Floor(0.2, Inverse)},
+		{"spark", espLevel(stattypes.Spark), Floor(0.5, Linear)},
+	},
+	"maintain": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"damage", targetDamage, Floor(0.1, Power(0.5))},
+		{"ecto", espLevel(stattypes.Ecto), Floor(0.5, Linear)},
+		{"brn", trait(stattypes.BRN), Floor(0.5, Linear)},
+	},
+	"rebuild": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"brn", trait(stattypes.BRN), Floor(0.5, Linear)},
+	},
+	"attack": {
+		{"distance", targetDistance, Floor(0.1, InversePower(2))},
+		{"threat", zoneThreat, Floor(0.4, Linear)},
+		{"healthy", statLevel(stattypes.Pulse, stattypes.MaxPulse), Logistic(0.3, 12)},
+		{"agg", trait(stattypes.AGG), Floor(0.4, Linear)},
+	},
+	"sell": {
+		{"distance", targetDistance, Floor(0.2, Inverse)},
+		{"goods", sellableStock(50), Power(0.5)},
+	},
+	"roam": {
+		{"threat", zoneThreat, Floor(0.3, Inverse)},
+		{"nrg", trait(stattypes.NRG), Floor(0.5, Linear)},
+	},
+}
+
+// targetDistance is how far the target's nearest tile is, 0 with no target
+func targetDistance(a interfaces.IAction) float64 {
+	target := a.GetTarget()
+	if target == nil {
+		return 0
+	}
+	ax, ay := a.GetActor().GetPosition()
+	closest := target.GetBounds().ClosestTo(pathfinding.Point{X: ax, Y: ay})
+	return float64(utils.Abs(closest.X-ax)+utils.Abs(closest.Y-ay)) / considerationRange
+}
+
+// targetDamage is how much pulse the target has lost
+func targetDamage(a interfaces.IAction) float64 {
+	stats, ok := a.GetTarget().(interfaces.IStats)
+	if !ok {
+		return 0
+	}
+	maxPulse := stats.GetStat(stattypes.MaxPulse)
+	if maxPulse <= 0 {
+		return 0
+	}
+	return 1 - stats.GetStat(stattypes.Pulse)/maxPulse
+}
+
+// statLevel is how full one of the actor's stats is against its max
+func statLevel(stat, maxStat string) func(a interfaces.IAction) float64 {
+	return func(a interfaces.IAction) float64 {
+		stats, ok := a.GetActor().(interfaces.IStats)
+		if !ok {
+			return 1
+		}
+		maxValue := stats.GetStat(maxStat)
+		if maxValue <= 0 {
+			return 1
+		}
+		return stats.GetStat(stat) / maxValue
+	}
+}
+
+// espLevel is one of the actor's ecto, spark or pulse out of 1000
+func espLevel(stat string) func(a interfaces.IAction) float64 {
+	return func(a interfaces.IAction) float64 {
+		stats, ok := a.GetActor().(interfaces.IStats)
+		if !ok {
+			return 0.5
+		}
+		return stats.GetStat(stat) / 1000
+	}
+}
+
+// trait is a gotchi's personality trait (nrg, agg, spk or brn) out of 100.
+// Anything that isn't a gotchi sits in the middle.
+func trait(stat string) func(a interfaces.IAction) float64 {
+	return func(a interfaces.IAction) float64 {
+		if _, ok := a.GetActor().(interfaces.IGotchi); !ok {
+			return 0.5
+		}
+		stats, ok := a.GetActor().(interfaces.IStats)
+		if !ok {
+			return 0.5
+		}
+		return stats.GetStat(stat) / 100
+	}
+}
+
+// itemStock is how much of an item the actor carries against what counts as plenty
+func itemStock(item string, plenty int) func(a interfaces.IAction) float64 {
+	return func(a interfaces.IAction) float64 {
+		inventory, ok := a.GetActor().(interfaces.IInventory)
+		if !ok {
+			return 0
+		}
+		return float64(inventory.GetItemQuantity(item)) / float64(plenty)
+	}
+}
+
+// sellableStock is how many sellable items the actor carries against what
+// counts as a full load
+func sellableStock(plenty int) func(a interfaces.IAction) float64 {
+	return func(a interfaces.IAction) float64 {
+		inventory, ok := a.GetActor().(interfaces.IInventory)
+		if !ok {
+			return 0
+		}
+		total := 0
+		for _, item := range inventory.GetSellableItems() {
+			total += item.Quantity
+		}
+		return float64(total) / float64(plenty)
+	}
+}
+
+// zoneThreat is the threat level of the actor's zone
+func zoneThreat(a interfaces.IAction) float64 {
+	zone := a.GetActor().GetZone()
+	if zone == nil {
+		return 0
+	}
+	return float64(zone.GetThreatLevel()) / 100
+}
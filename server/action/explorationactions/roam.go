@@ -4,6 +4,7 @@ import (
 	"log"
 	"thereaalm/action"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
 	"thereaalm/stattypes"
 	"thereaalm/types"
 	"thereaalm/utils"
@@ -14,6 +15,7 @@ type RoamAction struct {
 	
 	Duration_s    float64
 	Timer_s float64
+	Destination *pathfinding.Point // Picked each time the roam is chosen
 }
 
 func NewRoamAction(actor interfaces.IEntity, target interfaces.IEntity, weighting float64,
@@ -56,46 +58,61 @@ func (r *RoamAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 	return true
 }
 
-func (r *RoamAction) Start() {
-	// attempt to find a new empty cell using the zone's FindNearbyEmptyCell method
-	// zone := r.Actor.GetZone() // Get the actor's zone
+// GetTravelGoal picks somewhere nearby to wander to, a new spot every time
+// the roam is chosen
+func (r *RoamAction) GetTravelGoal() (pathfinding.Goal, bool) {
+	r.Destination = nil
+
+	newX, newY, found := r.findDestination()
+	if !found {
+		return pathfinding.Goal{}, false
+	}
+
+	r.Destination = &pathfinding.Point{X: newX, Y: newY}
+	return pathfinding.Goal{Target: pathfinding.PointRect(*r.Destination)}, true
+}
+
+// findDestination finds an empty tile in roaming range
+func (r *RoamAction) findDestination() (int, int, bool) {
 	actorX, actorY := r.Actor.GetPosition()
 
 	// use ecto to govern roam radius (between 2 - 10)
 	actorStats, _ := r.Actor.(interfaces.IStats)
 	if actorStats == nil {
 		log.Printf("Roam assigned to actor with no stats!")
-		return
+		return 0, 0, false
 	}
 
 	// find delta from peak explorer ecto
 	actorEcto := actorStats.GetStat(stattypes.Ecto)
 
 	alpha := 1.0 - actorEcto / 1000
-	explorationRadius := 2 + int(alpha * 8.0)
 
 	if r.WorldManager == nil {
 		log.Println("Error: We do not have a valid WorldManager")
-		return
+		return 0, 0, false
 	}
 
-	// Use the zone's FindNearbyEmptyCell with radius 3
-	newX, newY, found := r.WorldManager.FindNearbyAvailablePosition(actorX, actorY, explorationRadius, 1)
-	if found {
-		// set direction to new position
-		r.Actor.SetDirectionToTargetPosition(newX, newY)
+	// gotchis don't wander as far in the dark
+	explorationRadius := 2 + int(alpha * 8.0 * r.WorldManager.GetDate().RoamRadius())
 
-		// Move the actor to the new position
-		r.Actor.SetPosition(newX, newY)
+	return r.WorldManager.FindNearbyAvailablePosition(r.Actor.GetRand(), actorX, actorY, explorationRadius, 1)
+}
 
-		// reduce pulse (our "stability")
-		actorStats.DeltaStat(stattypes.Pulse, -0.5)
+func (r *RoamAction) Start() {
+	actorStats, _ := r.Actor.(interfaces.IStats)
+	if actorStats == nil || r.Destination == nil {
+		return
 	}
+	r.Destination = nil
+
+	// reduce pulse (our "stability")
+	actorStats.DeltaStat(stattypes.Pulse, -0.5)
 }
 
 
 
-// Update moves the actor to the new location and completes the action after 5 seconds
+// Update waits out the roam once the actor has walked there, done after Timer_s
 func (r *RoamAction) Update(dt_s float64) bool {
 	r.Timer_s -= dt_s
 	if r.Timer_s <= 0 {
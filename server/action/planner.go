@@ -0,0 +1,105 @@
+package action
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/types"
+)
+
+// Goal oriented planning: when the action an actor wants can't run yet (a
+// builder with nothing to maintain an altar with), the planner chains other
+// actions whose effects meet its preconditions, e.g. chop, mine, then maintain.
+
+// maxPlanSteps is the longest chain of actions the planner will look for
+const maxPlanSteps = 4
+
+// planSearchLimit is how many partial plans the planner looks at before giving up
+const planSearchLimit = 256
+
+// Goal is something an actor works towards that might take a few actions to
+// get to. Weighting scales how much it wants the action that finishes it.
+type Goal struct {
+	Name       string
+	Conditions types.WorldState
+	Weighting  float64
+}
+
+// CurrentWorldState is what an actor knows about itself right now
+func CurrentWorldState(actor interfaces.IEntity) types.WorldState {
+	state := types.WorldState{}
+	if inventory, ok := actor.(interfaces.IInventory); ok {
+		for item, quantity := range *inventory.GetItemsMap() {
+			if quantity > 0 {
+				state[types.HasItem(item)] = true
+			}
+		}
+	}
+	return state
+}
+
+type planNode struct {
+	state types.WorldState
+	steps []interfaces.IAction
+	cost  float64
+}
+
+// Plan finds the cheapest chain of actions that gets from start to conditions,
+// nil if there isn't one (or conditions already hold). Each action is used at
+// most once and costs more the further away its target is.
+func Plan(start, conditions types.WorldState, actions []interfaces.IAction) []interfaces.IAction {
+	if start.Satisfies(conditions) {
+		return nil
+	}
+
+	frontier := []planNode{{state: start}}
+	for searched := 0; len(frontier) > 0 && searched < planSearchLimit; searched++ {
+		// take the most promising plan, earliest found wins ties
+		best := 0
+		for i, node := range frontier {
+			if planPriority(node, conditions) < planPriority(frontier[best], conditions) {
+				best = i
+			}
+		}
+		node := frontier[best]
+		frontier = append(frontier[:best], frontier[best+1:]...)
+
+		if node.state.Satisfies(conditions) {
+			return node.steps
+		}
+		if len(node.steps) >= maxPlanSteps {
+			continue
+		}
+
+		for _, action := range actions {
+			if planUses(node.steps, action) || !node.state.Satisfies(action.GetPreconditions()) {
+				continue
+			}
+			effects := action.GetEffects()
+			if len(effects) == 0 || node.state.Satisfies(effects) {
+				continue // wouldn't get us anywhere
+			}
+
+			steps := make([]interfaces.IAction, len(node.steps), len(node.steps)+1)
+			copy(steps, node.steps)
+			frontier = append(frontier, planNode{
+				state: node.state.Apply(effects),
+				steps: append(steps, action),
+				cost:  node.cost + 1 + targetDistance(action),
+			})
+		}
+	}
+	return nil
+}
+
+// planPriority is the plan's cost so far plus a step for each fact still missing
+func planPriority(node planNode, conditions types.WorldState) float64 {
+	return node.cost + float64(node.state.Unsatisfied(conditions))
+}
+
+func planUses(steps []interfaces.IAction, action interfaces.IAction) bool {
+	for _, step := range steps {
+		if step == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package action
+
+import (
+	"testing"
+	"thereaalm/interfaces"
+	"thereaalm/types"
+)
+
+// planStep is an action that only has preconditions and effects
+type planStep struct {
+	Action
+	pre, effects types.WorldState
+}
+
+func (s *planStep) GetPreconditions() types.WorldState { return s.pre }
+func (s *planStep) GetEffects() types.WorldState       { return s.effects }
+
+func newPlanStep(name string, pre, effects types.WorldState) *planStep {
+	return &planStep{Action: Action{Type: name}, pre: pre, effects: effects}
+}
+
+func TestPlan(t *testing.T) {
+	kekwood, alphaslate := types.HasItem("kekwood"), types.HasItem("alphaslate")
+	restored := types.Restored("altar")
+
+	chop := newPlanStep("chop", nil, types.WorldState{kekwood: true})
+	mine := newPlanStep("mine", nil, types.WorldState{alphaslate: true})
+	maintain := newPlanStep("maintain", types.WorldState{kekwood: true, alphaslate: true}, types.WorldState{restored: true})
+	forage := newPlanStep("forage", nil, types.WorldState{types.HasItem("fomoberry"): true})
+	actions := []interfaces.IAction{forage, chop, mine, maintain}
+
+	// each of a to e needs the one before, e is a step more than the planner chains
+	a := newPlanStep("a", nil, types.WorldState{"a": true})
+	b := newPlanStep("b", types.WorldState{"a": true}, types.WorldState{"b": true})
+	c := newPlanStep("c", types.WorldState{"b": true}, types.WorldState{"c": true})
+	d := newPlanStep("d", types.WorldState{"c": true}, types.WorldState{"d": true})
+	e := newPlanStep("e", types.WorldState{"d": true}, types.WorldState{"e": true})
+
+	tests := []struct {
+		name       string
+		start      types.WorldState
+		conditions types.WorldState
+		actions    []interfaces.IAction
+		want       []string
+	}{
+		{"empty handed builder", types.WorldState{}, types.WorldState{restored: true}, actions, []string{"chop", "mine", "maintain"}},
+		{"already has kekwood", types.WorldState{kekwood: true}, types.WorldState{restored: true}, actions, []string{"mine", "maintain"}},
+		{"has everything", types.WorldState{kekwood: true, alphaslate: true}, types.WorldState{restored: true}, actions, []string{"maintain"}},
+		{"already satisfied", types.WorldState{restored: true}, types.WorldState{restored: true}, actions, nil},
+		{"no way there", types.WorldState{}, types.WorldState{restored: true}, []interfaces.IAction{chop, maintain}, nil},
+		{"as long as allowed", types.WorldState{}, types.WorldState{"d": true}, []interfaces.IAction{a, b, c, d}, []string{"a", "b", "c", "d"}},
+		{"too long", types.WorldState{}, types.WorldState{"e": true}, []interfaces.IAction{a, b, c, d, e}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := Plan(tt.start, tt.conditions, tt.actions)
+			if len(steps) != len(tt.want) {
+				t.Fatalf("got %d steps, want %v", len(steps), tt.want)
+			}
+			for i, step := range steps {
+				if step.GetType() != tt.want[i] {
+					t.Fatalf("step %d is %s, want %v", i, step.GetType(), tt.want)
+				}
+			}
+		})
+	}
+}
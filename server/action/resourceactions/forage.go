@@ -75,19 +75,29 @@ func (a *ForageAction) IsValidTarget(potentialTarget interfaces.IEntity) bool {
 		return false	// action is complete we have invalid actor or target
 	}
 
-	// can move to target?
-	if !a.CanMoveToTargetEntity(potentialTarget) {
+	// resource entity is ready for collecting?
+	if !forageable.CanBeForaged() {
 		return false
 	}
 
-	// resource entity is ready for collecting?
-	if !forageable.CanBeForaged() {
+	// is there room to work on it?
+	if a.IsFullyBooked(potentialTarget) {
+		return false
+	}
+
+	// can move to target?
+	if !a.CanMoveToTargetEntity(potentialTarget) {
 		return false
 	}
 
 	return true
 }
 
+// GetEffects leaves the actor carrying some fomoberries
+func (a *ForageAction) GetEffects() types.WorldState {
+	return types.WorldState{types.HasItem("fomoberry"): true}
+}
+
 func (a *ForageAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 	itemHolder, _ := potentialActor.(interfaces.IInventory);
 
@@ -102,8 +112,8 @@ func (a *ForageAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 }
 
 func (a *ForageAction) Start() {
-	// move to target
-	a.TryMoveToTargetEntity(a.Target) 
+	// travel got us next to the target, face it
+	a.Actor.SetDirectionToTargetEntity(a.Target)
 }
 
 func (a *ForageAction) Update(dt_s float64) bool {
@@ -119,14 +129,17 @@ func (a *ForageAction) Update(dt_s float64) bool {
 		return true	// action is complete we have invalid actor or target
 	}
 
+	// bad weather slows the work down and wears the actor out faster
+	weatherEffects := a.GetWeatherEffects()
+
 	// remove some spark and pulse
 	if actorStats, ok := a.Actor.(interfaces.IStats); ok {
-		actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s)
-		actorStats.DeltaStat(stattypes.Pulse, -0.1*dt_s)
+		actorStats.DeltaStat(stattypes.Spark, -0.1*dt_s*weatherEffects.Drain)
+		actorStats.DeltaStat(stattypes.Pulse, -0.1*dt_s*weatherEffects.Drain)
 	}
 
 	// check duration expired
-	a.Timer_s -= dt_s
+	a.Timer_s -= dt_s / weatherEffects.ActionDuration
 	if a.Timer_s <= 0 {
 
 		typeRemoved, amountRemoved := forageable.Forage()
@@ -51,6 +51,11 @@ func (a *SellAction) IsValidTarget(potentialTarget interfaces.IEntity) bool {
 		return false	// action is complete we have invalid actor or target
 	}
 
+	// is there room to work on it?
+	if a.IsFullyBooked(potentialTarget) {
+		return false
+	}
+
 	// can move to target?
 	if !a.CanMoveToTargetEntity(potentialTarget) {
 		return false
@@ -81,7 +86,8 @@ func (a *SellAction) IsValidActor(potentialActor interfaces.IEntity) bool {
 func (a *SellAction) Start() {
 	a.Timer_s = a.Duration_s
 
-	a.TryMoveToTargetEntity(a.Target)
+	// travel got us next to the target, face it
+	a.Actor.SetDirectionToTargetEntity(a.Target)
 }
 
 func (a *SellAction) Update(dt_s float64) bool {
@@ -0,0 +1,120 @@
+package action
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+)
+
+// beginAction makes action the current one, booking a slot at its target if
+// it takes bookings. Actors that can walk set off towards its travel goal
+// first and only Start it once they get there.
+func (a *ActionPlan) beginAction(action interfaces.IAction) {
+	a.DropCurrentAction()
+	if !action.ClaimWorkSlot() {
+		return // someone got the last slot first
+	}
+	a.CurrentAction = action
+
+	mover, ok := action.GetActor().(interfaces.IMover)
+	if !ok {
+		// can't walk anywhere, so only start what's already in reach
+		if goal, hasGoal := action.GetTravelGoal(); hasGoal {
+			x, y := action.GetActor().GetPosition()
+			if !goal.ReachedBy(pathfinding.Point{X: x, Y: y}) {
+				a.DropCurrentAction()
+				return
+			}
+		}
+		action.Start()
+		return
+	}
+	mover.StopMoving()
+
+	goal, hasGoal := action.GetTravelGoal()
+	if hasGoal {
+		a.travelGoal = goal
+		if !a.setOff(mover, goal) {
+			a.DropCurrentAction()
+			return
+		}
+	}
+
+	if !a.Travelling {
+		action.Start()
+	}
+}
+
+// setOff starts the mover on a new path to goal. It returns false if there's
+// no way there (this tick at least).
+func (a *ActionPlan) setOff(mover interfaces.IMover, goal pathfinding.Goal) bool {
+	mover.StopMoving()
+
+	x, y := mover.GetPosition()
+	if goal.ReachedBy(pathfinding.Point{X: x, Y: y}) {
+		return true
+	}
+
+	zone := mover.GetZone()
+	if zone == nil {
+		return false
+	}
+
+	path, result := zone.FindPath(x, y, goal)
+	if result != pathfinding.Found {
+		return false
+	}
+	if len(path) > 0 {
+		mover.StartMoving(path)
+		a.Travelling = true
+	}
+	return true
+}
+
+// travel walks the actor along its path and starts the current action once
+// it arrives. Speed comes from the mover (stats and buffs) rather than dt_s
+// being scaled like it is for action updates.
+func (a *ActionPlan) travel(dt_s float64) {
+	action := a.CurrentAction
+	mover, ok := action.GetActor().(interfaces.IMover)
+	if !ok {
+		a.Travelling = false
+		action.Start()
+		return
+	}
+
+	zone := mover.GetZone()
+	arrived, blocked := mover.AdvanceMovement(dt_s, mover.GetMoveSpeed(), func(tile pathfinding.Point) bool {
+		if zone == nil || !zone.IsPositionAvailable(tile.X, tile.Y) {
+			return false
+		}
+		mover.SetDirectionToTargetPosition(tile.X, tile.Y)
+		mover.SetPosition(tile.X, tile.Y)
+		return true
+	})
+	if !arrived && !blocked {
+		return
+	}
+
+	// targets can wander off while we walk, so aim for where they are now
+	goal := a.travelGoal
+	if action.GetTarget() != nil {
+		goal, _ = action.GetTravelGoal()
+	}
+
+	// if we're not there yet find a way round whatever is in the way
+	a.Travelling = false
+	if !a.setOff(mover, goal) {
+		a.DropCurrentAction()
+		return
+	}
+	if a.Travelling {
+		return
+	}
+
+	// made it, make sure the target is still worth it
+	if target := action.GetTarget(); target != nil && !action.IsValidTarget(target) {
+		a.DropCurrentAction()
+		return
+	}
+	action.Start()
+}
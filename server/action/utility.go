@@ -0,0 +1,113 @@
+package action
+
+import (
+	"math"
+	"thereaalm/interfaces"
+)
+
+// Utility scoring: every action weighs up a few considerations (how far away
+// its target is, how the actor's doing, what's going on round it), each
+// shaped by a response curve into a 0-1 score. They're multiplied together
+// and by the action's Weighting to get how much the actor wants to do it.
+
+// Curve shapes how much a consideration's input (0-1) matters (0-1)
+type Curve func(x float64) float64
+
+// Linear rises straight from 0 to 1
+func Linear(x float64) float64 { return x }
+
+// Inverse falls straight from 1 to 0
+func Inverse(x float64) float64 { return 1 - x }
+
+// Power bends Linear, exponents over 1 only start caring once x gets high
+func Power(exponent float64) Curve {
+	return func(x float64) float64 { return math.Pow(x, exponent) }
+}
+
+// InversePower bends Inverse, exponents over 1 only start caring once x gets low
+func InversePower(exponent float64) Curve {
+	return func(x float64) float64 { return math.Pow(1-x, exponent) }
+}
+
+// Logistic is an S, low below midpoint and high above it. Steepness sets how
+// sharp the switch is, negative flips it.
+func Logistic(midpoint, steepness float64) Curve {
+	return func(x float64) float64 { return 1 / (1 + math.Exp(-steepness*(x-midpoint))) }
+}
+
+// Floor stops a curve scoring under min, so it can tip the balance without
+// ruling an action out on its own
+func Floor(min float64, curve Curve) Curve {
+	return func(x float64) float64 { return min + (1-min)*curve(x) }
+}
+
+// consideration is one thing an action weighs up when scoring itself
+type consideration struct {
+	name  string
+	input func(a interfaces.IAction) float64 // What's being measured, 0-1
+	curve Curve
+}
+
+// ConsiderationScore is one line of an action's score breakdown
+type ConsiderationScore struct {
+	Name  string  `json:"name"`
+	Input float64 `json:"input"`
+	Score float64 `json:"score"`
+}
+
+// ActionScore is what an action scored and why
+type ActionScore struct {
+	Score          float64              `json:"score"`
+	Considerations []ConsiderationScore `json:"considerations,omitempty"`
+}
+
+// ScoreAction scores an action against its type's considerations (see
+// considerations.go). Actions with none just score their Weighting.
+func ScoreAction(action interfaces.IAction) ActionScore {
+	considerations := actionConsiderations[action.GetType()]
+	breakdown := make([]ConsiderationScore, 0, len(considerations))
+
+	combined := 1.0
+	for _, c := range considerations {
+		input := clamp01(c.input(action))
+		score := clamp01(c.curve(input))
+		breakdown = append(breakdown, ConsiderationScore{Name: c.name, Input: input, Score: score})
+		combined *= score
+	}
+
+	// multiplying lots of scores drags everything down, make up some of the
+	// loss so actions with more considerations aren't punished for it
+	if n := len(considerations); n > 1 {
+		makeUp := (1 - combined) * (1 - 1/float64(n))
+		combined += makeUp * combined
+	}
+
+	return ActionScore{
+		Score:          action.GetWeighting() * combined,
+		Considerations: breakdown,
+	}
+}
+
+func clamp01(x float64) float64 {
+	if math.IsNaN(x) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, x))
+}
+
+// SelectionMode is how a plan picks between its scored actions
+type SelectionMode string
+
+const (
+	// SelectSoftmax rolls for an action, higher scores being more likely.
+	// Temperature sets how much more likely, near 0 is almost SelectHighest.
+	SelectSoftmax SelectionMode = "softmax"
+
+	// SelectHighest always takes the best scoring action
+	SelectHighest SelectionMode = "highest"
+)
+
+// defaultTemperature is the softmax temperature for plans that don't set one.
+// Scores are mostly under 1 so this keeps a bit of variety without letting
+// a poor choice win often.
+const defaultTemperature = 0.15
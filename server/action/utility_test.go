@@ -0,0 +1,81 @@
+package action
+
+import (
+	"math"
+	"testing"
+	"thereaalm/interfaces"
+)
+
+func TestCurves(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve Curve
+		x     float64
+		want  float64
+	}{
+		{"linear", Linear, 0.25, 0.25},
+		{"inverse", Inverse, 0.25, 0.75},
+		{"power squares", Power(2), 0.5, 0.25},
+		{"power roots", Power(0.5), 0.25, 0.5},
+		{"inverse power", InversePower(2), 0.5, 0.25},
+		{"logistic at its midpoint", Logistic(0.3, 10), 0.3, 0.5},
+		{"logistic well above", Logistic(0.3, 50), 0.9, 1},
+		{"logistic flipped", Logistic(0.3, -50), 0.9, 0},
+		{"floor at 0", Floor(0.2, Linear), 0, 0.2},
+		{"floor at 1", Floor(0.2, Linear), 1, 1},
+		{"floor halfway", Floor(0.2, Linear), 0.5, 0.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curve(tt.x); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("got %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixed is a consideration that always measures input
+func fixed(name string, input float64, curve Curve) consideration {
+	return consideration{name, func(interfaces.IAction) float64 { return input }, curve}
+}
+
+func TestScoreAction(t *testing.T) {
+	tests := []struct {
+		name           string
+		weighting      float64
+		considerations []consideration
+		want           float64
+	}{
+		{"no considerations scores its weighting", 0.7, nil, 0.7},
+		{"zero weighting", 0, []consideration{fixed("a", 1, Linear)}, 0},
+		{"one is taken as is", 2, []consideration{fixed("a", 0.5, Linear)}, 1},
+		{"curve shapes the input", 1, []consideration{fixed("a", 0.5, Power(2))}, 0.25},
+		// 0.25 made up by (1 - 0.25) * (1 - 1/2) of itself
+		{"two are made up for", 1, []consideration{fixed("a", 0.5, Linear), fixed("b", 0.5, Linear)}, 0.34375},
+		// 0.125 made up by (1 - 0.125) * (1 - 1/3) of itself
+		{"three are made up for more", 1, []consideration{
+			fixed("a", 0.5, Linear), fixed("b", 0.5, Linear), fixed("c", 0.5, Linear),
+		}, 0.125 + 0.125*0.875*2/3},
+		{"any zero rules it out", 1, []consideration{fixed("a", 1, Linear), fixed("b", 0, Linear)}, 0},
+		{"all ones stay at one", 3, []consideration{fixed("a", 1, Linear), fixed("b", 1, Linear)}, 3},
+		{"inputs are clamped", 1, []consideration{fixed("a", 4, Linear)}, 1},
+		{"NaN counts as zero", 1, []consideration{fixed("a", math.NaN(), Inverse)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actionType := "test " + tt.name
+			actionConsiderations[actionType] = tt.considerations
+			defer delete(actionConsiderations, actionType)
+
+			score := ScoreAction(&Action{Type: actionType, Weighting: tt.weighting})
+			if math.Abs(score.Score-tt.want) > 1e-9 {
+				t.Fatalf("got %g, want %g", score.Score, tt.want)
+			}
+			if len(score.Considerations) != len(tt.considerations) {
+				t.Fatalf("got %d considerations in the breakdown, want %d", len(score.Considerations), len(tt.considerations))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package action
+
+import (
+	"sort"
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/utils"
+)
+
+// slotCapacity is how many workers fit round target at once: what it
+// advertises, but never more than there are tiles round its edge
+func slotCapacity(slots interfaces.IWorkSlots, target interfaces.IEntity) int {
+	perimeter := len(target.GetBounds().Perimeter())
+	if capacity := slots.GetSlotCapacity(); capacity > 0 && capacity < perimeter {
+		return capacity
+	}
+	return perimeter
+}
+
+// IsFullyBooked is true if target takes bookings and every slot round it is
+// held by someone else
+func (a *Action) IsFullyBooked(target interfaces.IEntity) bool {
+	slots, ok := target.(interfaces.IWorkSlots)
+	if !ok {
+		return false
+	}
+	if _, held := slots.GetClaimedSlot(a.Actor.GetUUID()); held {
+		return false
+	}
+	return slots.CountOtherClaims(a.Actor.GetUUID()) >= slotCapacity(slots, target)
+}
+
+// ClaimWorkSlot books the actor the closest free tile next to the target.
+// Targets that don't take bookings (or no target at all) always succeed.
+func (a *Action) ClaimWorkSlot() bool {
+	slots, ok := a.Target.(interfaces.IWorkSlots)
+	if !ok {
+		return true
+	}
+
+	zone := a.Actor.GetZone()
+	if zone == nil {
+		return false
+	}
+
+	ax, ay := a.Actor.GetPosition()
+	var candidates []pathfinding.Point
+	for _, tile := range a.Target.GetBounds().Perimeter() {
+		if (tile.X == ax && tile.Y == ay) || zone.IsPositionAvailable(tile.X, tile.Y) {
+			candidates = append(candidates, tile)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return utils.Abs(candidates[i].X-ax)+utils.Abs(candidates[i].Y-ay) <
+			utils.Abs(candidates[j].X-ax)+utils.Abs(candidates[j].Y-ay)
+	})
+
+	_, ok = slots.ClaimSlot(a.Actor.GetUUID(), candidates, slotCapacity(slots, a.Target))
+	return ok
+}
+
+// ReleaseWorkSlot gives back the actor's slot at the target, if it has one
+func (a *Action) ReleaseWorkSlot() {
+	if slots, ok := a.Target.(interfaces.IWorkSlots); ok {
+		slots.ReleaseSlot(a.Actor.GetUUID())
+	}
+}
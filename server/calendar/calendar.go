@@ -0,0 +1,95 @@
+package calendar
+
+import (
+	"math"
+	"time"
+)
+
+// Phase is the part of the day
+type Phase string
+
+const (
+	Dawn  Phase = "dawn"
+	Day   Phase = "day"
+	Dusk  Phase = "dusk"
+	Night Phase = "night"
+)
+
+type Season string
+
+const (
+	Spring Season = "spring"
+	Summer Season = "summer"
+	Autumn Season = "autumn"
+	Winter Season = "winter"
+)
+
+var seasons = []Season{Spring, Summer, Autumn, Winter}
+
+// Hours (0-24) each phase starts at, night runs until dawn
+const (
+	DawnHour  = 5.0
+	DayHour   = 7.0
+	DuskHour  = 18.0
+	NightHour = 20.0
+)
+
+// Calendar turns game time into days and seasons
+type Calendar struct {
+	DayLength     time.Duration // Game time in one day
+	DaysPerSeason int
+	StartHour     float64 // Hour of day at game time 0
+}
+
+// Default is a 20 minute day with 7 day seasons, starting at 6am on day 1 of spring
+var Default = Calendar{
+	DayLength:     20 * time.Minute,
+	DaysPerSeason: 7,
+	StartHour:     6,
+}
+
+// Date is a point in game time in calendar terms. Day, DayOfSeason and Year count from 1.
+type Date struct {
+	Day         int     `json:"day"`
+	Hour        float64 `json:"hour"` // 0-24
+	Phase       Phase   `json:"phase"`
+	Season      Season  `json:"season"`
+	DayOfSeason int     `json:"dayOfSeason"`
+	Year        int     `json:"year"`
+}
+
+// DateAt returns the date at a game time
+func (c Calendar) DateAt(gameTime time.Duration) Date {
+	days := float64(gameTime)/float64(c.DayLength) + c.StartHour/24
+	dayIndex := int(math.Floor(days))
+	hour := (days - float64(dayIndex)) * 24
+
+	seasonIndex := dayIndex / c.DaysPerSeason
+	return Date{
+		Day:         dayIndex + 1,
+		Hour:        hour,
+		Phase:       PhaseAt(hour),
+		Season:      seasons[seasonIndex%len(seasons)],
+		DayOfSeason: dayIndex%c.DaysPerSeason + 1,
+		Year:        seasonIndex/len(seasons) + 1,
+	}
+}
+
+// PhaseAt returns the phase for an hour of the day
+func PhaseAt(hour float64) Phase {
+	switch {
+	case hour >= NightHour || hour < DawnHour:
+		return Night
+	case hour < DayHour:
+		return Dawn
+	case hour < DuskHour:
+		return Day
+	default:
+		return Dusk
+	}
+}
+
+// IsNight is true between dusk and dawn
+func (d Date) IsNight() bool {
+	return d.Phase == Night
+}
@@ -0,0 +1,50 @@
+package calendar
+
+// Gameplay rules that change with the time of day or year. They're kept
+// together here so the world's rhythm can be tuned in one place.
+
+// BerryRegrowRate scales how fast fomo berries grow back (2 = twice as fast)
+var BerryRegrowRate = map[Season]float64{
+	Spring: 1.5,
+	Summer: 1.0,
+	Autumn: 0.75,
+	Winter: 0.25,
+}
+
+// LickVoidSpawnInterval scales the time between lickquidator spawns, voids
+// are most active at night
+var LickVoidSpawnInterval = map[Phase]float64{
+	Dawn:  1.0,
+	Day:   1.25,
+	Dusk:  0.75,
+	Night: 0.5,
+}
+
+// RoamRadius scales how far gotchis wander, they stay close to home in the dark
+var RoamRadius = map[Phase]float64{
+	Dawn:  0.75,
+	Day:   1.0,
+	Dusk:  0.75,
+	Night: 0.5,
+}
+
+// VisionRadius scales how far entities can see
+var VisionRadius = map[Phase]float64{
+	Dawn:  0.75,
+	Day:   1.0,
+	Dusk:  0.75,
+	Night: 0.5,
+}
+
+// scale looks a multiplier up, anything missing counts as 1
+func scale[K comparable](table map[K]float64, key K) float64 {
+	if m, ok := table[key]; ok {
+		return m
+	}
+	return 1
+}
+
+func (d Date) BerryRegrowRate() float64       { return scale(BerryRegrowRate, d.Season) }
+func (d Date) LickVoidSpawnInterval() float64 { return scale(LickVoidSpawnInterval, d.Phase) }
+func (d Date) RoamRadius() float64            { return scale(RoamRadius, d.Phase) }
+func (d Date) VisionRadius() float64          { return scale(VisionRadius, d.Phase) }
@@ -0,0 +1,61 @@
+package components
+
+import "thereaalm/pathfinding"
+
+// Movement walks an entity along a path a tile at a time
+type Movement struct {
+	Path     []pathfinding.Point // Tiles still to walk, the next one first
+	Progress float64             // How far into the step onto Path[0], 0 to 1
+}
+
+func (m *Movement) StartMoving(path []pathfinding.Point) {
+	m.Path = path
+	m.Progress = 0
+}
+
+func (m *Movement) StopMoving() {
+	m.Path = nil
+	m.Progress = 0
+}
+
+func (m *Movement) IsMoving() bool {
+	return len(m.Path) > 0
+}
+
+func (m *Movement) GetPath() []pathfinding.Point {
+	return m.Path
+}
+
+// GetETA_s returns how many seconds are left at speed tiles per second
+func (m *Movement) GetETA_s(speed float64) float64 {
+	if !m.IsMoving() || speed <= 0 {
+		return 0
+	}
+	return (float64(len(m.Path)) - m.Progress) / speed
+}
+
+// AdvanceMovement walks dt_s worth of the path at speed tiles per second.
+// enter is called for each tile stepped onto and returns false if the tile is
+// blocked, in which case we stay where we are. It reports whether the path is
+// finished and whether we got blocked.
+func (m *Movement) AdvanceMovement(dt_s, speed float64, enter func(tile pathfinding.Point) bool) (arrived, blocked bool) {
+	if !m.IsMoving() {
+		return true, false
+	}
+
+	m.Progress += dt_s * speed
+	for m.Progress >= 1 && len(m.Path) > 0 {
+		if !enter(m.Path[0]) {
+			m.Progress = 0
+			return false, true
+		}
+		m.Path = m.Path[1:]
+		m.Progress--
+	}
+
+	if len(m.Path) == 0 {
+		m.Progress = 0
+		return true, false
+	}
+	return false, false
+}
@@ -0,0 +1,105 @@
+package components
+
+import (
+	"sync"
+	"thereaalm/pathfinding"
+
+	"github.com/google/uuid"
+)
+
+// WorkSlots books out the tiles round an entity to the workers heading for
+// it, so a crowd spreads out instead of everyone making for the same tile and
+// the ones who'd find it full don't pick it at all.
+//
+// Workers give their slot back by dropping the action that booked it, which
+// they also do when they die or leave the world.
+type WorkSlots struct {
+	SlotCapacity int // Workers allowed at once, 0 means one per tile round the edge
+
+	mu     sync.Mutex // Workers from a neighbouring zone can give slots back
+	claims []slotClaim
+}
+
+type slotClaim struct {
+	worker uuid.UUID
+	tile   pathfinding.Point
+}
+
+func (w *WorkSlots) GetSlotCapacity() int {
+	return w.SlotCapacity
+}
+
+// ClaimSlot books worker the first of candidates nobody else has, as long as
+// fewer than capacity workers already have one. A worker asking again gets
+// the slot it already has.
+func (w *WorkSlots) ClaimSlot(worker uuid.UUID, candidates []pathfinding.Point, capacity int) (pathfinding.Point, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if tile, ok := w.findLocked(worker); ok {
+		return tile, true
+	}
+	if len(w.claims) >= capacity {
+		return pathfinding.Point{}, false
+	}
+
+	for _, tile := range candidates {
+		if !w.isTakenLocked(tile) {
+			w.claims = append(w.claims, slotClaim{worker: worker, tile: tile})
+			return tile, true
+		}
+	}
+	return pathfinding.Point{}, false
+}
+
+// GetClaimedSlot returns the tile worker has booked, if any
+func (w *WorkSlots) GetClaimedSlot(worker uuid.UUID) (pathfinding.Point, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.findLocked(worker)
+}
+
+// CountOtherClaims returns how many workers other than worker have a slot
+func (w *WorkSlots) CountOtherClaims(worker uuid.UUID) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := 0
+	for _, claim := range w.claims {
+		if claim.worker != worker {
+			count++
+		}
+	}
+	return count
+}
+
+// ReleaseSlot gives back worker's slot, if it has one
+func (w *WorkSlots) ReleaseSlot(worker uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, claim := range w.claims {
+		if claim.worker == worker {
+			w.claims = append(w.claims[:i], w.claims[i+1:]...)
+			return
+		}
+	}
+}
+
+func (w *WorkSlots) findLocked(worker uuid.UUID) (pathfinding.Point, bool) {
+	for _, claim := range w.claims {
+		if claim.worker == worker {
+			return claim.tile, true
+		}
+	}
+	return pathfinding.Point{}, false
+}
+
+func (w *WorkSlots) isTakenLocked(tile pathfinding.Point) bool {
+	for _, claim := range w.claims {
+		if claim.tile == tile {
+			return true
+		}
+	}
+	return false
+}
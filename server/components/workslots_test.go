@@ -0,0 +1,102 @@
+package components
+
+import (
+	"testing"
+	"thereaalm/pathfinding"
+
+	"github.com/google/uuid"
+)
+
+func TestWorkSlots(t *testing.T) {
+	a, b, c := uuid.UUID{1}, uuid.UUID{2}, uuid.UUID{3}
+	tiles := []pathfinding.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}
+
+	type claim struct {
+		worker   uuid.UUID
+		capacity int
+		tiles    int // how many of tiles it asks for, 0 means all of them
+		wantTile pathfinding.Point
+		wantOK   bool
+	}
+	tests := []struct {
+		name    string
+		claims  []claim
+		release []uuid.UUID // given back before the last claim
+	}{
+		{"first free tile", []claim{
+			{a, 3, 0, tiles[0], true},
+			{b, 3, 0, tiles[1], true},
+		}, nil},
+		{"asking again keeps the slot", []claim{
+			{a, 3, 0, tiles[0], true},
+			{b, 3, 0, tiles[1], true},
+			{a, 3, 0, tiles[0], true},
+		}, nil},
+		{"full at capacity", []claim{
+			{a, 2, 0, tiles[0], true},
+			{b, 2, 0, tiles[1], true},
+			{c, 2, 0, pathfinding.Point{}, false},
+		}, nil},
+		{"full when out of tiles", []claim{
+			{a, 3, 1, tiles[0], true},
+			{b, 3, 1, pathfinding.Point{}, false},
+		}, nil},
+		{"released slot goes to the next worker", []claim{
+			{a, 2, 0, tiles[0], true},
+			{b, 2, 0, tiles[1], true},
+			{c, 2, 0, tiles[0], true},
+		}, []uuid.UUID{a}},
+		{"releasing without a slot changes nothing", []claim{
+			{a, 2, 0, tiles[0], true},
+			{b, 2, 0, tiles[1], true},
+			{c, 2, 0, pathfinding.Point{}, false},
+		}, []uuid.UUID{c}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w WorkSlots
+			for i, cl := range tt.claims {
+				if i == len(tt.claims)-1 {
+					for _, worker := range tt.release {
+						w.ReleaseSlot(worker)
+					}
+				}
+				candidates := tiles
+				if cl.tiles > 0 {
+					candidates = tiles[:cl.tiles]
+				}
+				tile, ok := w.ClaimSlot(cl.worker, candidates, cl.capacity)
+				if tile != cl.wantTile || ok != cl.wantOK {
+					t.Fatalf("claim %d: got %v %v, want %v %v", i, tile, ok, cl.wantTile, cl.wantOK)
+				}
+			}
+		})
+	}
+}
+
+// A worker that moves on to another target gives its old slot back
+func TestWorkSlotsRetarget(t *testing.T) {
+	a, b := uuid.UUID{1}, uuid.UUID{2}
+	tiles := []pathfinding.Point{{X: 0, Y: 0}}
+	var first, second WorkSlots
+
+	first.ClaimSlot(a, tiles, 1)
+	if _, ok := first.ClaimSlot(b, tiles, 1); ok {
+		t.Fatal("got a slot at a full target")
+	}
+
+	first.ReleaseSlot(a)
+	if _, ok := second.ClaimSlot(a, tiles, 1); !ok {
+		t.Fatal("couldn't book the new target")
+	}
+	if _, held := first.GetClaimedSlot(a); held {
+		t.Fatal("still holding a slot at the old target")
+	}
+	if n := first.CountOtherClaims(b); n != 0 {
+		t.Fatalf("old target has %d other claims, want 0", n)
+	}
+	if _, ok := first.ClaimSlot(b, tiles, 1); !ok {
+		t.Fatal("couldn't book the slot given back")
+	}
+}
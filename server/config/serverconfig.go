@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Persistence backends
+const (
+	PersistenceNone  = "none"
+	PersistenceFile  = "file"
+	PersistenceRedis = "redis"
+)
+
+// ServerConfig is everything that can differ between deployments. Values are
+// layered defaults < config file < environment < flags.
+type ServerConfig struct {
+	Port            string   `json:"port"`
+	WorkerCount     int      `json:"workerCount"`     // 0 = one per CPU
+	Seed            int64    `json:"seed"`            // 0 = pick one from the clock
+	SimulationSpeed float64  `json:"simulationSpeed"` // 1.0 = real time
+	TilemapManifest string   `json:"tilemapManifest"`
+	Scenario        string   `json:"scenario"` // Empty = start with an empty world
+	SubgraphURL     string   `json:"subgraphUrl"`
+	CORSOrigins     []string `json:"corsOrigins"` // "*" allows any origin
+
+	ShutdownTimeout_s float64           `json:"shutdownTimeout_s"` // How long a graceful shutdown may take
+	Persistence       PersistenceConfig `json:"persistence"`
+	Threat            ThreatConfig      `json:"threat"`
+}
+
+// PersistenceConfig picks where world state is saved
+type PersistenceConfig struct {
+	Backend   string `json:"backend"`   // none, file or redis
+	FilePath  string `json:"filePath"`  // file backend only
+	RedisAddr string `json:"redisAddr"` // redis backend only
+}
+
+// DefaultServerConfig returns the config used when nothing else is set
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Port:            "8080",
+		WorkerCount:     0,
+		Seed:            0,
+		SimulationSpeed: 1.0,
+		TilemapManifest: "../shared/tilemaps/manifest.json",
+		Scenario:        "../shared/scenarios/default.json",
+		SubgraphURL:     "https://subgraph.satsuma-prod.com/tWYl5n5y04oz/aavegotchi/aavegotchi-core-matic/api",
+		CORSOrigins:     []string{"*"},
+
+		ShutdownTimeout_s: 30,
+		Persistence: PersistenceConfig{
+			Backend:   PersistenceNone,
+			FilePath:  "world_state.json",
+			RedisAddr: "localhost:6379",
+		},
+		Threat: DefaultThreatConfig(),
+	}
+}
+
+// envPrefix is prepended to every environment variable we read
+const envPrefix = "THEREAALM_"
+
+// LoadServerConfig builds the config from a config file, the environment and
+// command line args (without the program name). The file comes from -config or
+// THEREAALM_CONFIG and is optional. The result is validated.
+func LoadServerConfig(args []string, getenv func(string) string) (*ServerConfig, error) {
+	cfg := DefaultServerConfig()
+
+	fs := flag.NewFlagSet("thereaalm", flag.ContinueOnError)
+	configPath := fs.String("config", getenv(envPrefix+"CONFIG"), "path to a JSON config file")
+	port := fs.String("port", "", "API server port")
+	workers := fs.Int("workers", 0, "zone update workers (0 = one per CPU)")
+	seed := fs.Int64("seed", 0, "world seed (0 = pick one from the clock)")
+	speed := fs.Float64("speed", 0, "simulation speed multiplier")
+	manifest := fs.String("tilemap-manifest", "", "tilemap manifest file")
+	scenario := fs.String("scenario", "", "scenario file")
+	subgraph := fs.String("subgraph-url", "", "aavegotchi subgraph URL")
+	cors := fs.String("cors-origins", "", "comma separated allowed CORS origins")
+	shutdownTimeout := fs.Float64("shutdown-timeout", 0, "seconds a graceful shutdown may take")
+	backend := fs.String("persistence", "", "persistence backend (none, file or redis)")
+	filePath := fs.String("persistence-file", "", "file for the file persistence backend")
+	redisAddr := fs.String("redis-addr", "", "address for the redis persistence backend")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(getenv); err != nil {
+		return nil, err
+	}
+
+	// only flags that were actually passed override what we have
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Port = *port
+		case "workers":
+			cfg.WorkerCount = *workers
+		case "seed":
+			cfg.Seed = *seed
+		case "speed":
+			cfg.SimulationSpeed = *speed
+		case "tilemap-manifest":
+			cfg.TilemapManifest = *manifest
+		case "scenario":
+			cfg.Scenario = *scenario
+		case "subgraph-url":
+			cfg.SubgraphURL = *subgraph
+		case "cors-origins":
+			cfg.CORSOrigins = splitList(*cors)
+		case "shutdown-timeout":
+			cfg.ShutdownTimeout_s = *shutdownTimeout
+		case "persistence":
+			cfg.Persistence.Backend = *backend
+		case "persistence-file":
+			cfg.Persistence.FilePath = *filePath
+		case "redis-addr":
+			cfg.Persistence.RedisAddr = *redisAddr
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays the fields set in a JSON config file
+func (cfg *ServerConfig) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overlays any THEREAALM_* environment variables that are set
+func (cfg *ServerConfig) loadEnv(getenv func(string) string) error {
+	var errs []error
+	env := func(name string) (string, bool) {
+		value := getenv(envPrefix + name)
+		return value, value != ""
+	}
+
+	if v, ok := env("PORT"); ok {
+		cfg.Port = v
+	}
+	if v, ok := env("WORKERS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sWORKERS: %q is not an integer", envPrefix, v))
+		}
+		cfg.WorkerCount = n
+	}
+	if v, ok := env("SEED"); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sSEED: %q is not an integer", envPrefix, v))
+		}
+		cfg.Seed = n
+	}
+	if v, ok := env("SPEED"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sSPEED: %q is not a number", envPrefix, v))
+		}
+		cfg.SimulationSpeed = f
+	}
+	if v, ok := env("TILEMAP_MANIFEST"); ok {
+		cfg.TilemapManifest = v
+	}
+	if v, ok := env("SCENARIO"); ok {
+		cfg.Scenario = v
+	}
+	if v, ok := env("SUBGRAPH_URL"); ok {
+		cfg.SubgraphURL = v
+	}
+	if v, ok := env("CORS_ORIGINS"); ok {
+		cfg.CORSOrigins = splitList(v)
+	}
+	if v, ok := env("SHUTDOWN_TIMEOUT_S"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sSHUTDOWN_TIMEOUT_S: %q is not a number", envPrefix, v))
+		}
+		cfg.ShutdownTimeout_s = f
+	}
+	if v, ok := env("PERSISTENCE"); ok {
+		cfg.Persistence.Backend = v
+	}
+	if v, ok := env("PERSISTENCE_FILE"); ok {
+		cfg.Persistence.FilePath = v
+	}
+	if v, ok := env("REDIS_ADDR"); ok {
+		cfg.Persistence.RedisAddr = v
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid value at once
+func (cfg *ServerConfig) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		invalid("port: %q is not a valid port", cfg.Port)
+	}
+	if cfg.WorkerCount < 0 {
+		invalid("workerCount: must not be negative, got %d", cfg.WorkerCount)
+	}
+	if cfg.SimulationSpeed <= 0 {
+		invalid("simulationSpeed: must be positive, got %g", cfg.SimulationSpeed)
+	}
+	if cfg.TilemapManifest == "" {
+		invalid("tilemapManifest: must be set")
+	}
+	if u, err := url.Parse(cfg.SubgraphURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("subgraphUrl: %q is not an http(s) URL", cfg.SubgraphURL)
+	}
+	if len(cfg.CORSOrigins) == 0 {
+		invalid("corsOrigins: must list at least one origin (use \"*\" for any)")
+	}
+	for _, origin := range cfg.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			invalid("corsOrigins: %q is not an origin like https://example.com", origin)
+		}
+	}
+
+	if cfg.ShutdownTimeout_s <= 0 {
+		invalid("shutdownTimeout_s: must be positive, got %g", cfg.ShutdownTimeout_s)
+	}
+
+	switch cfg.Persistence.Backend {
+	case PersistenceNone:
+	case PersistenceFile:
+		if cfg.Persistence.FilePath == "" {
+			invalid("persistence.filePath: must be set for the file backend")
+		}
+	case PersistenceRedis:
+		if cfg.Persistence.RedisAddr == "" {
+			invalid("persistence.redisAddr: must be set for the redis backend")
+		}
+	default:
+		invalid("persistence.backend: %q must be one of none, file or redis", cfg.Persistence.Backend)
+	}
+
+	cfg.Threat.validate(invalid)
+
+	return errors.Join(errs...)
+}
+
+// splitList splits a comma separated list, dropping blanks
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
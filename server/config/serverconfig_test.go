@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadServerConfigPrecedence(t *testing.T) {
+	defaults := DefaultServerConfig()
+
+	tests := []struct {
+		name  string
+		file  string // config file contents, none if empty
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, cfg *ServerConfig)
+	}{
+		{"defaults", "", nil, nil, func(t *testing.T, cfg *ServerConfig) {
+			if !reflect.DeepEqual(cfg, defaults) {
+				t.Fatalf("got %+v, want the defaults", cfg)
+			}
+		}},
+		{"file over defaults", `{"port": "9000", "seed": 5, "persistence": {"backend": "file"}}`, nil, nil,
+			func(t *testing.T, cfg *ServerConfig) {
+				if cfg.Port != "9000" || cfg.Seed != 5 || cfg.Persistence.Backend != PersistenceFile {
+					t.Fatalf("got port %s seed %d backend %s", cfg.Port, cfg.Seed, cfg.Persistence.Backend)
+				}
+				// the rest of the nested struct keeps its defaults
+				if cfg.Persistence.FilePath != defaults.Persistence.FilePath || cfg.SimulationSpeed != defaults.SimulationSpeed {
+					t.Fatalf("file cleared fields it didn't set: %+v", cfg)
+				}
+			}},
+		{"env over file", `{"port": "9000", "seed": 5}`, map[string]string{"PORT": "9100", "SPEED": "2.5"}, nil,
+			func(t *testing.T, cfg *ServerConfig) {
+				if cfg.Port != "9100" || cfg.Seed != 5 || cfg.SimulationSpeed != 2.5 {
+					t.Fatalf("got port %s seed %d speed %g", cfg.Port, cfg.Seed, cfg.SimulationSpeed)
+				}
+			}},
+		{"flags over env", `{"port": "9000", "seed": 5}`, map[string]string{"PORT": "9100", "SEED": "6"}, []string{"-port", "9200"},
+			func(t *testing.T, cfg *ServerConfig) {
+				if cfg.Port != "9200" || cfg.Seed != 6 {
+					t.Fatalf("got port %s seed %d", cfg.Port, cfg.Seed)
+				}
+			}},
+		{"flags set to zero still count", "", map[string]string{"WORKERS": "4"}, []string{"-workers", "0"},
+			func(t *testing.T, cfg *ServerConfig) {
+				if cfg.WorkerCount != 0 {
+					t.Fatalf("got %d workers", cfg.WorkerCount)
+				}
+			}},
+		{"lists", `{"corsOrigins": ["https://a.example"]}`, map[string]string{"CORS_ORIGINS": " https://b.example, ,https://c.example"}, nil,
+			func(t *testing.T, cfg *ServerConfig) {
+				if want := []string{"https://b.example", "https://c.example"}; !reflect.DeepEqual(cfg.CORSOrigins, want) {
+					t.Fatalf("got %v, want %v", cfg.CORSOrigins, want)
+				}
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadServerConfig(configArgs(t, tt.file, tt.args), getenvFrom(tt.env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadServerConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		wantErr []string // all of these appear in the error
+	}{
+		{"unknown file field", `{"prot": "9000"}`, nil, nil, []string{`unknown field "prot"`}},
+		{"bad env number", "", map[string]string{"WORKERS": "lots", "SPEED": "fast"}, nil,
+			[]string{`THEREAALM_WORKERS: "lots" is not an integer`, `THEREAALM_SPEED: "fast" is not a number`}},
+		{"unknown flag", "", nil, []string{"-colour", "blue"}, []string{"-colour"}},
+		{"every invalid value", `{"port": "0", "workerCount": -1, "simulationSpeed": 0, "subgraphUrl": "ftp://x",
+			"corsOrigins": ["example.com"], "shutdownTimeout_s": 0, "persistence": {"backend": "s3"}}`, nil, nil, []string{
+			`port: "0"`, "workerCount: must not be negative", "simulationSpeed: must be positive",
+			`subgraphUrl: "ftp://x"`, `corsOrigins: "example.com"`, "shutdownTimeout_s: must be positive",
+			`persistence.backend: "s3"`,
+		}},
+		{"backend needs its setting", "", map[string]string{"PERSISTENCE": "redis"}, []string{"-redis-addr", ""},
+			[]string{"persistence.redisAddr: must be set"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadServerConfig(configArgs(t, tt.file, tt.args), getenvFrom(tt.env))
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+// configArgs writes file (if there is one) and points -config at it
+func configArgs(t *testing.T, file string, args []string) []string {
+	t.Helper()
+	if file == "" {
+		return args
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return append([]string{"-config", path}, args...)
+}
+
+// getenvFrom reads THEREAALM_ variables from env, named without the prefix
+func getenvFrom(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[strings.TrimPrefix(name, envPrefix)]
+	}
+}
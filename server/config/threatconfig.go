@@ -0,0 +1,97 @@
+package config
+
+import "fmt"
+
+// Threat events a threshold can fire
+const (
+	ThreatEventWarning = "warning" // Only recorded in the zone's threat history
+	ThreatEventRaid    = "raid"    // Lickquidators in the zone attack the nearest altar
+)
+
+// ThreatConfig tunes how a zone's threat level (0-100) is scored and what
+// happens as it rises
+type ThreatConfig struct {
+	EnemyWeight          float64 `json:"enemyWeight"`          // Per live lickquidator
+	LickVoidWeight       float64 `json:"lickVoidWeight"`       // Per active lickvoid
+	DestroyedAltarWeight float64 `json:"destroyedAltarWeight"` // Per destroyed altar
+	GotchiDeathWeight    float64 `json:"gotchiDeathWeight"`    // Per recent gotchi death
+	Scale                float64 `json:"scale"`                // Weighted score that reads as a threat of ~63
+	DeathHalfLife_s      float64 `json:"deathHalfLife_s"`      // How quickly gotchi deaths are forgotten
+	DecayHalfLife_s      float64 `json:"decayHalfLife_s"`      // How quickly threat falls once the danger is gone
+
+	Thresholds []ThreatThreshold `json:"thresholds"`
+}
+
+// ThreatThreshold fires an event when a zone's threat rises past Level. It
+// re-arms once threat drops back below Level - ThreatHysteresis.
+type ThreatThreshold struct {
+	Level      int     `json:"level"`
+	Event      string  `json:"event"`      // warning or raid
+	Cooldown_s float64 `json:"cooldown_s"` // Game time between firings
+	RaidSize   int     `json:"raidSize"`   // Lickquidators sent, raid only
+}
+
+const ThreatHysteresis = 10
+
+// DefaultThreatConfig returns the threat tuning used when nothing else is set
+func DefaultThreatConfig() ThreatConfig {
+	return ThreatConfig{
+		EnemyWeight:          1,
+		LickVoidWeight:       3,
+		DestroyedAltarWeight: 10,
+		GotchiDeathWeight:    5,
+		Scale:                300,
+		DeathHalfLife_s:      600,
+		DecayHalfLife_s:      120,
+		Thresholds: []ThreatThreshold{
+			{Level: 50, Event: ThreatEventWarning, Cooldown_s: 300},
+			{Level: 75, Event: ThreatEventRaid, Cooldown_s: 600, RaidSize: 10},
+		},
+	}
+}
+
+// validate adds a message to invalid for each bad value
+func (tc *ThreatConfig) validate(invalid func(format string, args ...interface{})) {
+	weights := []struct {
+		name  string
+		value float64
+	}{
+		{"enemyWeight", tc.EnemyWeight},
+		{"lickVoidWeight", tc.LickVoidWeight},
+		{"destroyedAltarWeight", tc.DestroyedAltarWeight},
+		{"gotchiDeathWeight", tc.GotchiDeathWeight},
+	}
+	for _, weight := range weights {
+		if weight.value < 0 {
+			invalid("threat.%s: must not be negative, got %g", weight.name, weight.value)
+		}
+	}
+	if tc.Scale <= 0 {
+		invalid("threat.scale: must be positive, got %g", tc.Scale)
+	}
+	if tc.DeathHalfLife_s <= 0 {
+		invalid("threat.deathHalfLife_s: must be positive, got %g", tc.DeathHalfLife_s)
+	}
+	if tc.DecayHalfLife_s <= 0 {
+		invalid("threat.decayHalfLife_s: must be positive, got %g", tc.DecayHalfLife_s)
+	}
+
+	for i, threshold := range tc.Thresholds {
+		path := fmt.Sprintf("threat.thresholds[%d]", i)
+		if threshold.Level < 1 || threshold.Level > 100 {
+			invalid("%s.level: must be between 1 and 100, got %d", path, threshold.Level)
+		}
+		if threshold.Cooldown_s < 0 {
+			invalid("%s.cooldown_s: must not be negative, got %g", path, threshold.Cooldown_s)
+		}
+		switch threshold.Event {
+		case ThreatEventWarning:
+		case ThreatEventRaid:
+			if threshold.RaidSize <= 0 {
+				invalid("%s.raidSize: must be positive for a raid, got %d", path, threshold.RaidSize)
+			}
+		default:
+			invalid("%s.event: %q must be warning or raid", path, threshold.Event)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package entity
 
 import (
+	"thereaalm/components"
 	"thereaalm/entity/entitystate"
 	"thereaalm/stattypes"
+	"thereaalm/types"
 
 	"github.com/google/uuid"
 )
@@ -11,22 +13,24 @@ import (
 
 type Altar struct {
 	Entity
+	components.WorkSlots // Room to work round it
 	Stats stattypes.Stats
 	entitystate.State
 	BuffRange int // Add range field
 }
 
-func NewAltar(x, y int) *Altar {
+func NewAltar(id uuid.UUID, x, y int) *Altar {
 	newStats := stattypes.NewStats()
 	newStats.SetStat(stattypes.Pulse, 1000)
 	newStats.SetStat(stattypes.MaxPulse, 1000)
 
 	return &Altar{
         Entity: Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "altar",
 			X: x,
 			Y: y,
+			Footprint: types.CenteredFootprint(5),
         },
 		Stats: *newStats,
 		State: entitystate.Active,
@@ -3,7 +3,10 @@ package entity
 import (
 	"log"
 	"math"
+	"math/rand"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/types"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +20,8 @@ type Entity struct {
 	CurrentZone interfaces.IZone
 	Direction string
 	WorldManager interfaces.IWorldManager
+	Rand *rand.Rand // per-entity random stream, assigned by the zone on add
+	Footprint types.Footprint // Tiles covered around X, Y, zero means a single tile
 }
 
 func (e *Entity) GetUUID() uuid.UUID { return e.ID }
@@ -30,6 +35,18 @@ func (e *Entity) SetPosition(x, y int) {
 	e.Y = y
 }
 
+func (e *Entity) GetFootprint() types.Footprint {
+	if e.Footprint.W <= 0 || e.Footprint.H <= 0 {
+		return types.SingleTile
+	}
+	return e.Footprint
+}
+
+// GetBounds returns every tile the entity covers
+func (e *Entity) GetBounds() pathfinding.Rect {
+	return e.GetFootprint().RectAt(e.X, e.Y)
+}
+
 func (e *Entity) GetCustomData() interface{} {
     return nil
 }
@@ -50,17 +67,23 @@ func (e *Entity) GetWorldManager() interfaces.IWorldManager {
     return e.WorldManager
 }
 
+func (e *Entity) SetRand(r *rand.Rand) {
+    e.Rand = r
+}
+
+func (e *Entity) GetRand() *rand.Rand {
+    return e.Rand
+}
+
 func (e *Entity) GetSnapshotData() interface {} {
 	return nil
 }
 
+// IsNextToTargetEntity checks if we're on a tile touching any edge of the
+// target's footprint (left, right, up, down, not diagonal)
 func (e *Entity) IsNextToTargetEntity(target interfaces.IEntity) bool {
     ax, ay := e.GetPosition()
-    bx, by := target.GetPosition()
-
-    // Check if the entities are next to each other (left, right, up, down)
-    return (ax == bx && (ay == by+1 || ay == by-1)) || // Vertical check (up, down)
-           (ay == by && (ax == bx+1 || ax == bx-1))   // Horizontal check (left, right)
+    return target.GetBounds().IsNextTo(pathfinding.Point{X: ax, Y: ay})
 }
 
 func (e *Entity) SetDirectionToTargetEntity(target interfaces.IEntity) {
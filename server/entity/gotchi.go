@@ -3,6 +3,7 @@ package entity
 import (
 	// "log"
 	"log"
+	"math"
 	"strconv"
 	"thereaalm/action"
 	"thereaalm/components"
@@ -21,6 +22,7 @@ type Gotchi struct {
     Entity
 	action.ActionPlan
 	components.Inventory
+	components.Movement
 	Stats stattypes.Stats
 	GotchiId string
 	Name string
@@ -34,7 +36,7 @@ type Gotchi struct {
 	GASP int
 }
 
-func NewGotchi(x, y int, subgraphGotchiData web3.SubgraphGotchiData) *Gotchi {
+func NewGotchi(id uuid.UUID, x, y int, subgraphGotchiData web3.SubgraphGotchiData) *Gotchi {
 	// add item holder
 	newItemHolder := components.NewInventory()
 
@@ -67,7 +69,7 @@ func NewGotchi(x, y int, subgraphGotchiData web3.SubgraphGotchiData) *Gotchi {
 	// make new gotchi
 	return &Gotchi{
         Entity: Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "gotchi",
 			X: x,
 			Y: y,
@@ -171,11 +173,13 @@ func (e *Gotchi) DeltaStat(name string, value float64) {
 
 		// set gotchi state to dead
 		e.State = entitystate.Dead
+		e.StopMoving()
+		e.DropCurrentAction()
 
 		// move gotchi to new location out of the way of entities
 		currX, currY := e.GetPosition()
 		newX, newY, found := 
-			e.GetWorldManager().FindNearbyAvailablePosition(currX, currY, 7, 1)
+			e.GetWorldManager().FindNearbyAvailablePosition(e.GetRand(), currX, currY, 7, 1)
 		if found {
 			// set direction to new position
 			e.SetDirection("down")
@@ -186,6 +190,26 @@ func (e *Gotchi) DeltaStat(name string, value float64) {
 	}
 }
 
+// gotchiBaseMoveSpeed is tiles per second for a gotchi on 500 ecto with no buffs
+const gotchiBaseMoveSpeed = 2.0
+
+// GetMoveSpeed scales with ecto (half speed when empty, 1.5x when full) and buffs
+func (g *Gotchi) GetMoveSpeed() float64 {
+	ectoMultiplier := 0.5 + g.Stats.GetStat(stattypes.Ecto) / 1000
+	return gotchiBaseMoveSpeed * ectoMultiplier * g.GetEffectiveSpeedMultiplier()
+}
+
+// gotchiBaseVisionRadius is how far a gotchi with average eyes (EYS and EYC
+// of 50) sees in clear daylight
+const gotchiBaseVisionRadius = 16.0
+
+// GetBaseVisionRadius grows the further eye shape and colour are from average,
+// the rarest eyes see 10 tiles further
+func (g *Gotchi) GetBaseVisionRadius() float64 {
+	rarity := math.Abs(g.Stats.GetStat(stattypes.EYS)-50) + math.Abs(g.Stats.GetStat(stattypes.EYC)-50)
+	return gotchiBaseVisionRadius + min(rarity, 100)/10
+}
+
 // IBuffConsumer methods
 func (g *Gotchi) GetEffectiveSpeedMultiplier() float64 {
     return g.BuffMultiplier
@@ -265,6 +289,11 @@ func (g *Gotchi) GetJob() string {
 	return g.Job
 }
 
+// GetGotchiID returns the aavegotchi token id
+func (g *Gotchi) GetGotchiID() string {
+	return g.GotchiId
+}
+
 func GetBRSMultiplier(subgraphData web3.SubgraphGotchiData) float64 {
 	brs, _ := strconv.Atoi(subgraphData.WithSetsRarityScore)
 	if brs < 500 {
@@ -17,11 +17,12 @@ type Lickquidator struct {
     Entity
 	action.ActionPlan
 	components.Inventory
+	components.Movement
 	stattypes.Stats
 	entitystate.State
 }
 
-func NewLickquidator(x, y int) *Lickquidator {
+func NewLickquidator(id uuid.UUID, x, y int) *Lickquidator {
 	// make them hold the "Tongue" item
 	newInventory := components.NewInventory()
 	newInventory.Items["tongue"] = 1
@@ -35,7 +36,7 @@ func NewLickquidator(x, y int) *Lickquidator {
 
     return &Lickquidator{
         Entity: Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "lickquidator",
 			X: x,
 			Y: y,
@@ -49,6 +50,20 @@ func NewLickquidator(x, y int) *Lickquidator {
     }
 }
 
+// lickquidators all shamble along at the same speed
+const lickquidatorMoveSpeed = 1.5
+
+func (l *Lickquidator) GetMoveSpeed() float64 {
+	return lickquidatorMoveSpeed
+}
+
+// lickquidators are a bit short sighted
+const lickquidatorVisionRadius = 12.0
+
+func (l *Lickquidator) GetBaseVisionRadius() float64 {
+	return lickquidatorVisionRadius
+}
+
 func (l *Lickquidator) GetSnapshotData() interface{} {
 	return struct {
 		Name string `json:"name"`
@@ -80,8 +95,8 @@ func (l *Lickquidator) Update(dt_s float64) {
             zone.RemoveEntity(l)
         }
         // Clean up the ActionPlan to prevent memory leaks
+        l.ActionPlan.DropCurrentAction()
         l.ActionPlan.Actions = nil
-        l.ActionPlan.CurrentAction = nil
         return
     }
 }
@@ -119,7 +134,7 @@ func (e *Lickquidator) DeltaStat(name string, value float64) {
             zone.RemoveEntity(e)
         }
         // Clean up the ActionPlan to prevent memory leaks
+        e.ActionPlan.DropCurrentAction()
         e.ActionPlan.Actions = nil
-        e.ActionPlan.CurrentAction = nil
 	}
 }
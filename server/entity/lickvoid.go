@@ -1,12 +1,13 @@
 package entity
 
 import (
-	"math/rand"
+	"thereaalm/components"
 	"thereaalm/action/combatactions"
 	"thereaalm/entity/entitystate"
 	"thereaalm/interfaces"
 	"thereaalm/stattypes"
 	"thereaalm/types"
+	"thereaalm/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,29 +17,30 @@ import (
 
 type LickVoid struct {
 	Entity
+	components.WorkSlots // Room to work round it
 	Stats stattypes.Stats
 	entitystate.State
 	SpawnInterval_s float64
-	LastSpawnTime time.Duration
 	MaxAliveSpawns  int
 	SpawnedLicks    []interfaces.IEntity
+	spawnEvent interfaces.EventID // next spawn attempt on our zone's scheduler
 }
 
-func NewLickVoid(x, y int) *LickVoid {
+func NewLickVoid(id uuid.UUID, x, y int) *LickVoid {
 	newStats := stattypes.NewStats()
 	newStats.SetStat(stattypes.Pulse, 1000)
 	newStats.SetStat(stattypes.MaxPulse, 1000)
 
 	return &LickVoid{
         Entity: Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "lickvoid",
 			X: x,
 			Y: y,
+			Footprint: types.CenteredFootprint(3),
         },
 		Stats: *newStats,
 		State: entitystate.Active,
-		LastSpawnTime: 0,
 		SpawnInterval_s: 5,
 		MaxAliveSpawns:  5,
 		SpawnedLicks:    []interfaces.IEntity{},
@@ -64,6 +66,27 @@ func (e *LickVoid) Update(dt_s float64) {
 		return
 	}
 
+	// spawning runs off our zone's scheduler, start it if it isn't going
+	// (first update, after being inactive or after being removed and re-added)
+	if !e.CurrentZone.GetScheduler().IsScheduled(e.spawnEvent) {
+		e.scheduleSpawn()
+	}
+}
+
+// scheduleSpawn queues the next spawn attempt, voids spawn faster at night
+func (e *LickVoid) scheduleSpawn() {
+	interval_s := e.SpawnInterval_s * e.WorldManager.GetDate().LickVoidSpawnInterval()
+	e.spawnEvent = e.CurrentZone.GetScheduler().ScheduleAfter(
+		time.Duration(interval_s*float64(time.Second)), interfaces.PriorityNormal, e.ID, e.trySpawn)
+}
+
+// trySpawn spawns a lickquidator next to us if we're under our alive limit
+func (e *LickVoid) trySpawn() {
+	if e.State != entitystate.Active {
+		return
+	}
+	defer e.scheduleSpawn()
+
 	// Clean up removed Lickquidators (nil or no longer in a zone)
 	filtered := e.SpawnedLicks[:0]
 	for _, l := range e.SpawnedLicks {
@@ -78,35 +101,29 @@ func (e *LickVoid) Update(dt_s float64) {
 		return
 	}
 
-	// Spawn if interval has passed
-	if e.WorldManager.Since(e.LastSpawnTime) >= time.Duration(e.SpawnInterval_s)*time.Second {
-		corners := [][2]int{
-			{1, 1}, {1, -1}, {-1, 1}, {-1, -1},
-		}
+	corners := [][2]int{
+		{1, 1}, {1, -1}, {-1, 1}, {-1, -1},
+	}
 
-		// shuffle corners
-		rand.Shuffle(len(corners), func(i, j int) {
-			corners[i], corners[j] = corners[j], corners[i]
-		})
+	// shuffle corners
+	e.GetRand().Shuffle(len(corners), func(i, j int) {
+		corners[i], corners[j] = corners[j], corners[i]
+	})
 
-		for _, offset := range corners {
-			spawnX := e.X + offset[0]
-			spawnY := e.Y + offset[1]
+	for _, offset := range corners {
+		spawnX := e.X + offset[0]
+		spawnY := e.Y + offset[1]
 
-			if e.WorldManager.IsPositionAvailable(spawnX, spawnY) {
-				lick := e.generateGenericLickquidator(spawnX, spawnY)
-				e.SpawnedLicks = append(e.SpawnedLicks, lick)
-				break // stop after successful spawn
-			}
+		if e.WorldManager.IsPositionAvailable(spawnX, spawnY) {
+			lick := e.generateGenericLickquidator(spawnX, spawnY)
+			e.SpawnedLicks = append(e.SpawnedLicks, lick)
+			break // stop after successful spawn
 		}
-
-		e.LastSpawnTime = e.WorldManager.Now()
 	}
 }
 
-
 func (e *LickVoid) generateGenericLickquidator(x, y int) interfaces.IEntity {
-	lickquidator := NewLickquidator(x, y)
+	lickquidator := NewLickquidator(utils.NewUUID(e.GetRand()), x, y)
 	e.GetWorldManager().AddEntity(lickquidator)
 
 	lickquidator.AddActionToPlan(combatactions.NewAttackAction(lickquidator, nil, 0.5,
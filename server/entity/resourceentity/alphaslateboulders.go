@@ -1,22 +1,25 @@
 package resourceentity
 
 import (
+	"thereaalm/components"
 	"thereaalm/entity"
 
 	"github.com/google/uuid"
 )
 type AlphaSlateBoulders struct {
 	entity.Entity
+	components.WorkSlots // Room to work round it
 }
 
-func NewAlphaSlateBoulders(x, y int) *AlphaSlateBoulders {
+func NewAlphaSlateBoulders(id uuid.UUID, x, y int) *AlphaSlateBoulders {
 	return &AlphaSlateBoulders{
         Entity: entity.Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "alphaslateboulders",
 			X: x,
 			Y: y,
         },
+		WorkSlots: components.WorkSlots{SlotCapacity: 3},
     }
 }
 
@@ -4,6 +4,7 @@ import (
 	// "log"
 	"thereaalm/components"
 	"thereaalm/entity"
+	"thereaalm/interfaces"
 	"thereaalm/utils"
 	"time"
 
@@ -12,19 +13,20 @@ import (
 type FomoBerryBush struct {
 	entity.Entity
 	components.Inventory
+	components.WorkSlots // Room to work round it
 	MaxBerries int
 	RegrowInterval_s time.Duration
 	RegrowAmount int
-	TimeOfLastRegrow time.Duration
+	regrowEvent interfaces.EventID
 }
 
-func NewFomoBerryBush(x, y int) *FomoBerryBush {
+func NewFomoBerryBush(id uuid.UUID, x, y int) *FomoBerryBush {
 	newInventory := components.NewInventory()
 	newInventory.Items["fomoberry"] = 50
 
 	return &FomoBerryBush{
         Entity: entity.Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "fomoberrybush",
 			X: x,
 			Y: y,
@@ -33,6 +35,7 @@ func NewFomoBerryBush(x, y int) *FomoBerryBush {
 		RegrowInterval_s: 20 * time.Second,
 		RegrowAmount: 10,
 		Inventory: *newInventory,
+		WorkSlots: components.WorkSlots{SlotCapacity: 2},
     }
 }
 
@@ -52,19 +55,32 @@ func (b *FomoBerryBush) GetSnapshotData() interface{} {
 	}
 }
 
-func (b *FomoBerryBush) Update(dt_s float64) {
-	if b.WorldManager.Since(b.TimeOfLastRegrow) >= b.RegrowInterval_s {
-		b.Items["fomoberry"] += b.RegrowAmount
-		if b.Items["fomoberry"] > b.MaxBerries {
-			b.Items["fomoberry"] = b.MaxBerries
-		}
+// scheduleRegrow queues the next regrow unless we're full or one is already queued
+func (b *FomoBerryBush) scheduleRegrow() {
+	scheduler := b.CurrentZone.GetScheduler()
+	if b.Items["fomoberry"] >= b.MaxBerries || scheduler.IsScheduled(b.regrowEvent) {
+		return
 	}
+
+	// berries grow back faster in spring and rain, and barely at all in winter
+	rate := b.WorldManager.GetDate().BerryRegrowRate() * b.CurrentZone.GetWeather().Effects().Regrow
+	delay := time.Duration(float64(b.RegrowInterval_s) / rate)
+	b.regrowEvent = scheduler.ScheduleAfter(delay, interfaces.PriorityNormal, b.ID, b.regrow)
+}
+
+func (b *FomoBerryBush) regrow() {
+	b.Items["fomoberry"] += b.RegrowAmount
+	if b.Items["fomoberry"] > b.MaxBerries {
+		b.Items["fomoberry"] = b.MaxBerries
+	}
+	b.scheduleRegrow()
 }
 
 func (b *FomoBerryBush) Forage() (string, int) {
 	harvestAmount := utils.Min(5, b.Items["fomoberry"])
 
 	b.RemoveItem("fomoberry", harvestAmount)
+	b.scheduleRegrow()
 
 	if harvestAmount > 0 {
 		return "fomoberry", harvestAmount
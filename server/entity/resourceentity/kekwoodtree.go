@@ -5,6 +5,7 @@ import (
 	"thereaalm/components"
 	"thereaalm/entity"
 	"thereaalm/entity/entitystate"
+	"thereaalm/interfaces"
 	"thereaalm/utils"
 	"time"
 
@@ -13,25 +14,26 @@ import (
 type KekWoodTree struct {
 	entity.Entity
 	components.Inventory
+	components.WorkSlots // Room to work round it
 	MaxWood int
 	RegrowDuration_s time.Duration
-	TimeOfDepletion time.Duration
 	State entitystate.State
 }
 
-func NewKekWoodTree(x, y int) *KekWoodTree {
+func NewKekWoodTree(id uuid.UUID, x, y int) *KekWoodTree {
 	newInventory := components.NewInventory()
 	newInventory.Items["kekwood"] = 100
 
 	return &KekWoodTree{
         Entity: entity.Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "kekwoodtree",
 			X: x,
 			Y: y,
         },
 		MaxWood: 100,
 		Inventory: *newInventory,
+		WorkSlots: components.WorkSlots{SlotCapacity: 2},
 		State: entitystate.Active,
     }
 }
@@ -52,22 +54,10 @@ func (b *KekWoodTree) GetSnapshotData() interface{} {
 	}
 }
 
-func (b *KekWoodTree) Update(dt_s float64) {
-
-	// check if we're at 0 
-	if b.State == entitystate.Active {
-		if b.Items["kekwood"] <= 0 {
-			b.State = entitystate.Regrowing
-			b.TimeOfDepletion = b.WorldManager.Now()
-		}
-	}
-
-	if b.State == entitystate.Regrowing {
-		if b.WorldManager.Since(b.TimeOfDepletion) >= b.RegrowDuration_s {
-			b.Items["kekwood"] = b.MaxWood
-			b.State = entitystate.Active
-		}
-	}
+// regrow fills us back up once RegrowDuration_s has passed since we ran out
+func (b *KekWoodTree) regrow() {
+	b.Items["kekwood"] = b.MaxWood
+	b.State = entitystate.Active
 }
 
 func (b *KekWoodTree) Chop() (string, int) {
@@ -75,6 +65,13 @@ func (b *KekWoodTree) Chop() (string, int) {
 
 	b.RemoveItem("kekwood", chopAmount)
 
+	// check if we're at 0
+	if b.State == entitystate.Active && b.Items["kekwood"] <= 0 {
+		b.State = entitystate.Regrowing
+		delay := time.Duration(float64(b.RegrowDuration_s) / b.CurrentZone.GetWeather().Effects().Regrow)
+		b.CurrentZone.GetScheduler().ScheduleAfter(delay, interfaces.PriorityNormal, b.ID, b.regrow)
+	}
+
 	if chopAmount > 0 {
 		return "kekwood", chopAmount
 	} else {
@@ -6,6 +6,7 @@ import (
 	"thereaalm/entity/entitystate"
 	"thereaalm/interfaces"
 	"thereaalm/stattypes"
+	"thereaalm/types"
 
 	"github.com/google/uuid"
 )
@@ -14,10 +15,11 @@ type Shop struct {
     Entity
 	Stats stattypes.Stats
 	components.Inventory
+	components.WorkSlots // Room to work round it
 	entitystate.State
 }
 
-func NewShop(x, y int) *Shop {
+func NewShop(id uuid.UUID, x, y int) *Shop {
 	// start show with gold
 	itemHolder := components.NewInventory()
 	itemHolder.Items["gold"] = 10000
@@ -28,10 +30,11 @@ func NewShop(x, y int) *Shop {
 
     return &Shop{
         Entity: Entity{
-            ID:   uuid.New(),
+            ID:   id,
             Type: "shop",
             X: x,
             Y: y,
+            Footprint: types.CenteredFootprint(3),
         },
         Inventory: *itemHolder,
 		Stats: *newStats,
@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"thereaalm/pathfinding"
 	"thereaalm/types"
 )
 
@@ -23,8 +24,13 @@ type IAction interface {
     GetFallbackTargetSpec() *types.TargetSpec
     SetFallbackTargetSpec(fallbackTargetspec *types.TargetSpec)
 
+    GetPreconditions() types.WorldState
+    GetEffects() types.WorldState
+
+    GetTravelGoal() (pathfinding.Goal, bool)
+    ClaimWorkSlot() bool
+    ReleaseWorkSlot()
+    IsFullyBooked(target IEntity) bool
     CanMoveToTargetEntity(target IEntity) bool
-    TryMoveToTargetEntity(target IEntity) bool
     CanMoveToTargetPosition(x, y int) bool 
-    TryMoveToTargetPosition(x, y int) bool 
 }
\ No newline at end of file
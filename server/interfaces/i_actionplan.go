@@ -4,5 +4,7 @@ package interfaces
 type IActionPlan interface {
     AddActionToPlan(a IAction)
     SelectNextAction()
+    InterruptWith(a IAction)
+    DropCurrentAction()
     ProcessActions(dt_s float64)
 }
@@ -1,6 +1,10 @@
 package interfaces
 
 import (
+	"math/rand"
+	"thereaalm/pathfinding"
+	"thereaalm/types"
+
 	"github.com/google/uuid"
 )
 
@@ -11,11 +15,15 @@ type IEntity interface {
     Update(dt_s float64)
     GetPosition() (int, int)
     SetPosition(x, y int)
+    GetFootprint() types.Footprint
+    GetBounds() pathfinding.Rect
     GetSnapshotData() interface{}
     GetZone() IZone
     SetZone(zone IZone)
     SetWorldManager(wm IWorldManager)
     GetWorldManager() IWorldManager
+    GetRand() *rand.Rand
+    SetRand(r *rand.Rand)
     IsNextToTargetEntity(target IEntity) bool
     SetDirection(direction string)
     GetDirection() string
@@ -2,4 +2,5 @@ package interfaces
 
 type IGotchi interface {
 	GetJob() string
+	GetGotchiID() string
 }
\ No newline at end of file
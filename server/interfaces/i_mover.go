@@ -0,0 +1,16 @@
+package interfaces
+
+import "thereaalm/pathfinding"
+
+// IMover is for entities that walk to where their actions happen instead of
+// appearing there (see components.Movement)
+type IMover interface {
+    IEntity
+    GetMoveSpeed() float64 // Tiles per second, buffs included
+    StartMoving(path []pathfinding.Point)
+    StopMoving()
+    IsMoving() bool
+    GetPath() []pathfinding.Point
+    GetETA_s(speed float64) float64
+    AdvanceMovement(dt_s, speed float64, enter func(tile pathfinding.Point) bool) (arrived, blocked bool)
+}
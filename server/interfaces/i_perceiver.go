@@ -0,0 +1,8 @@
+package interfaces
+
+// IPerceiver is for entities with eyesight of their own, anything else sees
+// the world's default distance (see Zone.GetVisionRadius)
+type IPerceiver interface {
+    IEntity
+    GetBaseVisionRadius() float64 // Tiles in clear daylight
+}
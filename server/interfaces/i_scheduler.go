@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventID identifies a scheduled event so it can be cancelled. 0 is never used.
+type EventID uint64
+
+// Event priorities, events due in the same tick run lowest value first
+const (
+	PriorityHigh   = -10
+	PriorityNormal = 0
+	PriorityLow    = 10
+)
+
+// IScheduler runs callbacks at a point in game time (WorldManager.Now()).
+// Owner is the entity the event belongs to (uuid.Nil for none), an owner's
+// events follow it between zones and are cancelled when it's removed.
+type IScheduler interface {
+	ScheduleAt(at time.Duration, priority int, owner uuid.UUID, fn func()) EventID
+	ScheduleAfter(delay time.Duration, priority int, owner uuid.UUID, fn func()) EventID
+	ScheduleRepeating(interval time.Duration, priority int, owner uuid.UUID, fn func()) EventID
+	Cancel(id EventID) bool
+	CancelOwner(owner uuid.UUID) int
+	IsScheduled(id EventID) bool
+}
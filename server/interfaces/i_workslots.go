@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+    "thereaalm/pathfinding"
+
+    "github.com/google/uuid"
+)
+
+// IWorkSlots is for targets with only so much room to work round them.
+// Workers book a tile next to the target when they pick it and give it back
+// when they're done (see components.WorkSlots).
+type IWorkSlots interface {
+    GetSlotCapacity() int // Workers allowed at once, 0 means one per tile round the edge
+    ClaimSlot(worker uuid.UUID, candidates []pathfinding.Point, capacity int) (pathfinding.Point, bool)
+    GetClaimedSlot(worker uuid.UUID) (pathfinding.Point, bool)
+    CountOtherClaims(worker uuid.UUID) int
+    ReleaseSlot(worker uuid.UUID)
+}
@@ -1,17 +1,29 @@
 package interfaces
 
-import "time"
+import (
+	"math/rand"
+	"thereaalm/calendar"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type IWorldManager interface {
 	Now() time.Duration
 	Since(startTime time.Duration) time.Duration
+	GetDate() calendar.Date
+	GetTick() uint64
 	SetSimulationSpeed(multiplier float64)
+	GetScheduler() IScheduler
 
 	AddEntity(e IEntity)
 	RemoveEntity(e IEntity)
+	GetEntityByUUID(id uuid.UUID) IEntity
+	GetGotchiByTokenID(tokenID string) IEntity
 
 	// utility functions 
 	IsPositionAvailable(x, y int) bool
-	FindNearbyAvailablePosition(x, y, radius, minimumGap int) (int, int, bool)
+	IsPositionInWorld(x, y int) bool
+	FindNearbyAvailablePosition(rng *rand.Rand, x, y, radius, minimumGap int) (int, int, bool)
 	GetDistance(x1, y1, x2, y2 int) int
 }
\ No newline at end of file
@@ -1,9 +1,21 @@
 package interfaces
 
 import (
+	"math/rand"
+	"thereaalm/pathfinding"
+	"thereaalm/weather"
+
 	"github.com/google/uuid"
 )
 
+// DistanceMetric is how spatial queries measure radius
+type DistanceMetric int
+
+const (
+	Manhattan DistanceMetric = iota // |dx| + |dy|, same as GetDistance
+	Euclidean
+)
+
 type IZone interface {
 	AddEntity(e IEntity)
 	RemoveEntity(e IEntity)
@@ -17,9 +29,18 @@ type IZone interface {
 	Update(dt_s float64)
 	
 	IsPositionAvailable(zoneX, zoneY int) bool
-	FindNearbyEntities(zoneX, zoneY, radius int) []IEntity
-	FindNearbyAvailablePosition(zoneX, zoneY, radius, minGap int) (int, int, bool) 
-	TryGetEmptyTileNextToTargetEntity(target IEntity) (int, int, bool)
+	FindEntitiesInRadius(x, y, radius int, metric DistanceMetric) []IEntity
+	FindEntitiesInRect(minX, minY, maxX, maxY int) []IEntity
+	FindNearestEntities(x, y int, entityType string, k, maxRadius int, match func(e IEntity) bool) []IEntity
+	FindPerceivedEntities(observer IEntity, entityType string) []IEntity
+	GetVisionRadius(observer IEntity) int
+	CanPerceive(observer, target IEntity) bool
+	HasLineOfSight(x0, y0, x1, y1 int) bool
+	FindNearbyAvailablePosition(rng *rand.Rand, zoneX, zoneY, radius, minGap int) (int, int, bool)
+	FindPath(fromX, fromY int, goal pathfinding.Goal) ([]pathfinding.Point, pathfinding.Result)
+	FindPathToEntity(fromX, fromY int, target IEntity) ([]pathfinding.Point, pathfinding.Result)
+	AreConnected(x0, y0, x1, y1 int) bool
+	IsReachable(fromX, fromY int, target IEntity) bool
 
 	GetEntityByUUID(uuid uuid.UUID) IEntity 
 	GetEntitiesByType(entityType string) []IEntity 
@@ -28,10 +49,12 @@ type IZone interface {
 	GetDistance(x1, y1, x2, y2 int) int
 
 	GetWorldManager() IWorldManager
+	GetScheduler() IScheduler
 
 	AddObstacle(x, y int)
 	RemoveObstacle(x, y int)
 	IsObstacle(x, y int) bool
 
 	GetThreatLevel() int
+	GetWeather() weather.State
 }
\ No newline at end of file
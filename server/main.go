@@ -1,23 +1,47 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"thereaalm/config"
 	"thereaalm/network"
+	"thereaalm/storage"
+	"thereaalm/web3"
 	"thereaalm/world"
+	"time"
 )
 
 func main() {
 	log.Println("Starting The Reaalm...")
 
+	// Load config (defaults < config file < env < flags)
+	cfg, err := config.LoadServerConfig(os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	web3.SubgraphURL = cfg.SubgraphURL
+
+	persister, err := storage.NewPersister(cfg.Persistence)
+	if err != nil {
+		log.Fatalf("Invalid persistence config: %v", err)
+	}
+
 	// Create and run the world manager
-	worldManager := world.NewWorldManager(0) // Use all CPU cores
+	// - the seed is logged on startup so any run can be reproduced
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	worldManager := world.NewWorldManager(cfg.WorkerCount, seed, cfg.TilemapManifest, cfg.Scenario)
+	worldManager.SetSimulationSpeed(cfg.SimulationSpeed)
+	worldManager.Threat = cfg.Threat
 	worldManager.Run()
 
 	// start the api server
-	network.StartAPIServer(worldManager, "8080")
+	apiServer := network.StartAPIServer(worldManager, cfg.Port, cfg.CORSOrigins)
 
 	// Create a channel to listen for interrupt signals (e.g., Ctrl+C)
 	sigChan := make(chan os.Signal, 1)
@@ -25,5 +49,26 @@ func main() {
 
 	// Block until an interrupt signal is received
 	<-sigChan
-	log.Println("Received shutdown signal, exiting...")
+	log.Println("Received shutdown signal, shutting down...")
+
+	// Everything below has to finish within the shutdown timeout
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(cfg.ShutdownTimeout_s*float64(time.Second)))
+	defer cancel()
+
+	// Stop taking commands, finish the current tick, save and drain the workers
+	exitCode := 0
+	if err := worldManager.Shutdown(ctx, persister); err != nil {
+		log.Printf("World shutdown failed: %v", err)
+		exitCode = 1
+	}
+
+	// Let in-flight requests finish (new commands are already being rejected)
+	if err := apiServer.Shutdown(ctx); err != nil {
+		log.Printf("API server shutdown failed: %v", err)
+		exitCode = 1
+	}
+
+	log.Println("Shutdown complete")
+	os.Exit(exitCode)
 }
\ No newline at end of file
@@ -1,52 +1,38 @@
 package network
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"thereaalm/config"
-	"thereaalm/interfaces"
-	"thereaalm/stattypes"
 	"thereaalm/world"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// ZoneSnapshot represents the state of a zone, including Gotchi positions.
-type ZoneSnapshot struct {
-	EntitySnapshots []EntitySnapshot `json:"entitySnapshots"`
-	ThreatLevel int `json:"threatlevel"`
-}
-
-// GotchiSnapshot captures the position and ID of a Gotchi in a zone.
-type EntitySnapshot struct {
-	ID     uuid.UUID `json:"id"`
-	ZoneID int       `json:"zoneId"`
-	Type   string    `json:"type"`
-	X      int       `json:"tileX"`
-	Y      int       `json:"tileY"`
-	Data   interface{} `json:"data"`
-}
-
 // ZoneMapResponse represents the structure of the zone map to be sent to the client.
 type ZoneMapResponse struct {
 	ZoneMap [][]string `json:"zoneMap"`
 }
 
 // StakeRequest represents the request body for staking/unstaking GHST.
+// The gotchi is picked by uuid, or by gotchiId (its token id) if uuid is unset.
 type StakeRequest struct {
 	UUID uuid.UUID `json:"uuid"`
+	GotchiID string `json:"gotchiId"`
 	GHSTAmount int `json:"ghstAmount"`
-	ZoneID int `json:"zoneId"`
 }
 
 // EatTreatRequest represents the request body for eating a treat.
 type EatTreatRequest struct {
 	TreatName string `json:"treatName"`
-	ZoneID int `json:"zoneId"`
 	UUID uuid.UUID `json:"uuid"`
+	GotchiID string `json:"gotchiId"`
 }
 
 // GotchiResponse represents the response for Gotchi-related actions.
@@ -58,32 +44,70 @@ type GotchiResponse struct {
 	Pulse int `json:"pulse"`
 }
 
-// StartAPIServer initializes the API server with the given world manager and port.
-func StartAPIServer(worldManager *world.WorldManager, port string) {
+// commandTimeout bounds how long a handler waits for the world to apply its
+// command or publish a snapshot (e.g. while the simulation is paused)
+const commandTimeout = 10 * time.Second
+
+// StartAPIServer initializes the API server with the given world manager, port and allowed CORS origins.
+// The returned server should be stopped with Shutdown.
+func StartAPIServer(worldManager *world.WorldManager, port string, corsOrigins []string) *http.Server {
 	// Create a new ServeMux to handle routes explicitly
 	mux := http.NewServeMux()
 
 	// Register handlers with CORS middleware
-	mux.HandleFunc("/zones/", withCORS(handleZoneSnapshot(worldManager)))
+	withCORS := newCORSMiddleware(corsOrigins)
+	mux.HandleFunc("/zones/", withCORS(handleZones(worldManager)))
 	mux.HandleFunc("/zonemap", withCORS(handleZoneMap()))
+	mux.HandleFunc("/entities/", withCORS(handleEntityLookup(worldManager, "entities")))
+	mux.HandleFunc("/gotchis/", withCORS(handleEntityLookup(worldManager, "gotchis")))
 	mux.HandleFunc("/gotchi/stake", withCORS(handleStakeGotchi(worldManager)))
 	mux.HandleFunc("/gotchi/unstake", withCORS(handleUnstakeGotchi(worldManager)))
 	mux.HandleFunc("/gotchi/eat", withCORS(handleEatTreat(worldManager)))
 
 	// Start the server
 	log.Printf("Starting API server on port %s...", port)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: mux,
+	}
 	go func() {
-		if err := http.ListenAndServe(":"+port, mux); err != nil {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("API server failed: %v", err)
 		}
 	}()
+
+	return server
+}
+
+// newCORSMiddleware returns a middleware that adds CORS headers for the allowed
+// origins to all responses and handles OPTIONS requests. "*" allows any origin.
+func newCORSMiddleware(allowedOrigins []string) func(http.HandlerFunc) http.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[origin] = true
+	}
+
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return withCORS(handler, allowAny, allowed)
+	}
 }
 
 // withCORS is a middleware that adds CORS headers to all responses and handles OPTIONS requests.
-func withCORS(handler http.HandlerFunc) http.HandlerFunc {
+func withCORS(handler http.HandlerFunc, allowAny bool, allowed map[string]bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if allowAny {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); allowed[origin] {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
@@ -113,6 +137,57 @@ func writeError(w http.ResponseWriter, message string, statusCode int) {
 	http.Error(w, message, statusCode)
 }
 
+// submitCommand queues a command on the world and waits for it to be applied.
+// Commands that time out are dropped, so a 504 means nothing changed.
+func submitCommand(r *http.Request, worldManager *world.WorldManager, cmd world.Command) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	return worldManager.SubmitAndWait(ctx, cmd)
+}
+
+// writeCommandError maps a command error to an HTTP status code
+func writeCommandError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, world.ErrZoneNotFound), errors.Is(err, world.ErrEntityNotFound):
+		writeError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, world.ErrInvalidCommand):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, world.ErrShuttingDown):
+		writeError(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, "Timed out waiting for the world to apply the request", http.StatusGatewayTimeout)
+	default:
+		writeError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// newGotchiResponse converts a command's stats result into a GotchiResponse
+func newGotchiResponse(result interface{}) GotchiResponse {
+	stats, _ := result.(world.GotchiStatsResult)
+	return GotchiResponse{
+		TreatTotal: stats.TreatTotal,
+		StakedGhst: stats.StakedGhst,
+		Ecto:       int(stats.Ecto),
+		Spark:      int(stats.Spark),
+		Pulse:      int(stats.Pulse),
+	}
+}
+
+// handleZones routes the /zones/{id}/... endpoints
+func handleZones(worldManager *world.WorldManager) http.HandlerFunc {
+	snapshot := handleZoneSnapshot(worldManager)
+	threat := handleZoneThreat(worldManager)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/threat") {
+			threat(w, r)
+			return
+		}
+		snapshot(w, r)
+	}
+}
+
 // handleZoneSnapshot returns a handler for the /zones/{id}/snapshot endpoint.
 func handleZoneSnapshot(worldManager *world.WorldManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -140,35 +215,60 @@ func handleZoneSnapshot(worldManager *world.WorldManager) http.HandlerFunc {
 			return
 		}
 
-		// Find the zone
-		zone := worldManager.Zones[zoneID]
-		if zone == nil {
-			writeError(w, "Zone not found: "+strconv.Itoa(zoneID), http.StatusNotFound)
-			return
-		}
+		// Grab the zone's latest published snapshot
+		ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+		defer cancel()
 
-		// Collect entities
-		var snapshot ZoneSnapshot
-		for _, entity := range zone.GetEntities() {
-			x, y := entity.GetPosition()
-			snapshot.EntitySnapshots = append(snapshot.EntitySnapshots, EntitySnapshot{
-				ID:     entity.GetUUID(),
-				ZoneID: zoneID,
-				Type: 	  entity.GetType(),
-				X:        x,
-				Y:        y,
-				Data: entity.GetSnapshotData(),
-			})
+		snapshot, err := worldManager.GetZoneSnapshot(ctx, zoneID)
+		if err != nil {
+			if errors.Is(err, world.ErrZoneNotFound) {
+				writeError(w, "Zone not found: "+strconv.Itoa(zoneID), http.StatusNotFound)
+				return
+			}
+			writeCommandError(w, err)
+			return
 		}
 
-		snapshot.ThreatLevel = zone.GetThreatLevel()
-		log.Printf("Zone %d, ThreatLevel: %d", zone.GetID(), zone.GetThreatLevel())
+		log.Printf("Zone %d, ThreatLevel: %d", zoneID, snapshot.ThreatLevel)
 
 		// Respond with JSON
 		writeJSON(w, snapshot)
 	}
 }
 
+// handleZoneThreat returns a handler for the /zones/{id}/threat endpoint, which
+// feeds the threat meter with the zone's threat history and events.
+func handleZoneThreat(worldManager *world.WorldManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Expected path: /zones/0/threat
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) != 4 || parts[1] != "zones" || parts[3] != "threat" {
+			writeError(w, "Invalid endpoint", http.StatusBadRequest)
+			return
+		}
+
+		zoneID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			writeError(w, "Invalid Zone ID", http.StatusBadRequest)
+			return
+		}
+
+		report, err := worldManager.GetThreatReport(zoneID)
+		if err != nil {
+			writeError(w, "Zone not found: "+strconv.Itoa(zoneID), http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, report)
+	}
+}
+
 // handleZoneMap returns a handler for the /zonemap endpoint.
 func handleZoneMap() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -196,6 +296,45 @@ func handleZoneMap() http.HandlerFunc {
 	}
 }
 
+// handleEntityLookup returns a handler for /entities/{uuid} and
+// /gotchis/{gotchiId}, which find an entity (and its zone) anywhere in the world.
+func handleEntityLookup(worldManager *world.WorldManager, collection string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only allow GET requests
+		if r.Method != http.MethodGet {
+			writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Expected path: /entities/{uuid} or /gotchis/{gotchiId}
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) != 3 || parts[1] != collection || parts[2] == "" {
+			writeError(w, "Invalid endpoint", http.StatusBadRequest)
+			return
+		}
+
+		var cmd world.LookupEntityCommand
+		if collection == "gotchis" {
+			cmd.GotchiID = parts[2]
+		} else {
+			id, err := uuid.Parse(parts[2])
+			if err != nil {
+				writeError(w, "Invalid UUID", http.StatusBadRequest)
+				return
+			}
+			cmd.UUID = id
+		}
+
+		result, err := submitCommand(r, worldManager, cmd)
+		if err != nil {
+			writeCommandError(w, err)
+			return
+		}
+
+		writeJSON(w, result)
+	}
+}
+
 // handleStakeGotchi handles staking GHST for a Gotchi.
 func handleStakeGotchi(worldManager *world.WorldManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -215,32 +354,18 @@ func handleStakeGotchi(worldManager *world.WorldManager) http.HandlerFunc {
 			return
 		}
 
-		// For simplicity, assume the Gotchi is in zone 0 and we find the first Gotchi
-		zone := worldManager.Zones[req.ZoneID]
-		if zone == nil {
-			writeError(w, "Zone not found", http.StatusNotFound)
-			return
-		}
-
-		gotchiEntity := zone.GetEntityByUUID(req.UUID)
-
-		if gotchiEntity == nil {
-			writeError(w, "Gotchi not found", http.StatusNotFound)
+		result, err := submitCommand(r, worldManager, world.StakeGHSTCommand{
+			UUID:     req.UUID,
+			GotchiID: req.GotchiID,
+			Amount:   req.GHSTAmount,
+		})
+		if err != nil {
+			writeCommandError(w, err)
 			return
 		}
 
-		gotchiStats, _ := gotchiEntity.(interfaces.IStats)
-		gotchiStats.DeltaStat(stattypes.StakedGHST, float64(req.GHSTAmount))
-
 		// Respond with updated Gotchi data
-		response := GotchiResponse{
-			TreatTotal: gotchiStats.GetStat(stattypes.TreatTotal),
-			StakedGhst: gotchiStats.GetStat(stattypes.StakedGHST),
-			Ecto: int(gotchiStats.GetStat(stattypes.Ecto)),
-			Spark: int(gotchiStats.GetStat(stattypes.Spark)),
-			Pulse: int(gotchiStats.GetStat(stattypes.Pulse)),
-		}
-		writeJSON(w, response)
+		writeJSON(w, newGotchiResponse(result))
 	}
 }
 
@@ -263,36 +388,18 @@ func handleUnstakeGotchi(worldManager *world.WorldManager) http.HandlerFunc {
 			return
 		}
 
-		// For simplicity, assume the Gotchi is in zone 0 and we find the first Gotchi
-		zone := worldManager.Zones[req.ZoneID]
-		if zone == nil {
-			writeError(w, "Zone not found", http.StatusNotFound)
-			return
-		}
-
-		gotchiEntity := zone.GetEntityByUUID(req.UUID)
-
-		if gotchiEntity == nil {
-			writeError(w, "Gotchi not found", http.StatusNotFound)
+		result, err := submitCommand(r, worldManager, world.UnstakeGHSTCommand{
+			UUID:     req.UUID,
+			GotchiID: req.GotchiID,
+			Amount:   req.GHSTAmount,
+		})
+		if err != nil {
+			writeCommandError(w, err)
 			return
 		}
 
-		gotchiStats, _ := gotchiEntity.(interfaces.IStats)
-		gotchiStats.DeltaStat(stattypes.StakedGHST, float64(-req.GHSTAmount))
-
-		if gotchiStats.GetStat(stattypes.StakedGHST) < 0 {
-			gotchiStats.SetStat(stattypes.StakedGHST, 0)
-		}
-
 		// Respond with updated Gotchi data
-		response := GotchiResponse{
-			TreatTotal: gotchiStats.GetStat(stattypes.TreatTotal),
-			StakedGhst: gotchiStats.GetStat(stattypes.StakedGHST),
-			Ecto: int(gotchiStats.GetStat(stattypes.Ecto)),
-			Spark: int(gotchiStats.GetStat(stattypes.Spark)),
-			Pulse: int(gotchiStats.GetStat(stattypes.Pulse)),
-		}
-		writeJSON(w, response)
+		writeJSON(w, newGotchiResponse(result))
 	}
 }
 
@@ -311,52 +418,22 @@ func handleEatTreat(worldManager *world.WorldManager) http.HandlerFunc {
 		}
 
 		// Validate treat name
-		validTreats := map[string]int{
-			"Sushi Roll": 500,
-			"Coconut":    500,
-			"Candy":      500,
-		}
-		_, ok := validTreats[req.TreatName]
-		if !ok {
+		if _, ok := world.TreatCosts[req.TreatName]; !ok {
 			writeError(w, "Invalid treat name", http.StatusBadRequest)
 			return
 		}
 
-		// For simplicity, assume the Gotchi is in zone 0 and we find the first Gotchi
-		zone := worldManager.Zones[req.ZoneID]
-		if zone == nil {
-			writeError(w, "Zone not found", http.StatusNotFound)
-			return
-		}
-
-		gotchiEntity := zone.GetEntityByUUID(req.UUID)
-
-		if gotchiEntity == nil {
-			writeError(w, "Gotchi not found", http.StatusNotFound)
+		result, err := submitCommand(r, worldManager, world.EatTreatCommand{
+			UUID:      req.UUID,
+			GotchiID:  req.GotchiID,
+			TreatName: req.TreatName,
+		})
+		if err != nil {
+			writeCommandError(w, err)
 			return
 		}
 
-		gotchiStats, _ := gotchiEntity.(interfaces.IStats)
-
-		if req.TreatName == "Sushi Roll" {
-			gotchiStats.DeltaStat(stattypes.Ecto, 100)
-		} else if req.TreatName == "Coconut" {
-			gotchiStats.DeltaStat(stattypes.Spark, 100)
-		} else if req.TreatName == "Candy" {
-			gotchiStats.DeltaStat(stattypes.Pulse, 100)
-		}
-		
-		// remove some treat
-		gotchiStats.DeltaStat(stattypes.TreatTotal, -float64(validTreats[req.TreatName]))
-
 		// Respond with updated Gotchi data
-		response := GotchiResponse{
-			TreatTotal: gotchiStats.GetStat(stattypes.TreatTotal),
-			StakedGhst: gotchiStats.GetStat(stattypes.StakedGHST),
-			Ecto: int(gotchiStats.GetStat(stattypes.Ecto)),
-			Spark: int(gotchiStats.GetStat(stattypes.Spark)),
-			Pulse: int(gotchiStats.GetStat(stattypes.Pulse)),
-		}
-		writeJSON(w, response)
+		writeJSON(w, newGotchiResponse(result))
 	}
 }
@@ -0,0 +1,242 @@
+// 4-way A* over a tile grid
+package pathfinding
+
+import (
+	"container/heap"
+)
+
+// Point is a tile in world coordinates
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Rect is a block of tiles, Min is the top left tile and W/H are at least 1
+type Rect struct {
+	Min  Point
+	W, H int
+}
+
+// PointRect returns the 1x1 rect covering p
+func PointRect(p Point) Rect {
+	return Rect{Min: p, W: 1, H: 1}
+}
+
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.Min.X && p.X < r.Min.X+r.W && p.Y >= r.Min.Y && p.Y < r.Min.Y+r.H
+}
+
+// Perimeter lists the tiles sharing an edge with r, clockwise from the top
+// left. A single tile gets its four neighbours.
+func (r Rect) Perimeter() []Point {
+	tiles := make([]Point, 0, 2*(r.W+r.H))
+	for x := r.Min.X; x < r.Min.X+r.W; x++ {
+		tiles = append(tiles, Point{X: x, Y: r.Min.Y - 1}) // above
+	}
+	for y := r.Min.Y; y < r.Min.Y+r.H; y++ {
+		tiles = append(tiles, Point{X: r.Min.X + r.W, Y: y}) // right
+	}
+	for x := r.Min.X + r.W - 1; x >= r.Min.X; x-- {
+		tiles = append(tiles, Point{X: x, Y: r.Min.Y + r.H}) // below
+	}
+	for y := r.Min.Y + r.H - 1; y >= r.Min.Y; y-- {
+		tiles = append(tiles, Point{X: r.Min.X - 1, Y: y}) // left
+	}
+	return tiles
+}
+
+// ClosestTo returns the tile of r nearest to p, p itself if it's inside
+func (r Rect) ClosestTo(p Point) Point {
+	return Point{
+		X: min(max(p.X, r.Min.X), r.Min.X+r.W-1),
+		Y: min(max(p.Y, r.Min.Y), r.Min.Y+r.H-1),
+	}
+}
+
+// distanceTo is the Manhattan distance from p to the closest tile of r
+func (r Rect) distanceTo(p Point) int {
+	dx := max(r.Min.X-p.X, 0, p.X-(r.Min.X+r.W-1))
+	dy := max(r.Min.Y-p.Y, 0, p.Y-(r.Min.Y+r.H-1))
+	return dx + dy
+}
+
+// IsNextTo reports whether p is outside r but shares an edge with one of its tiles
+func (r Rect) IsNextTo(p Point) bool {
+	return r.distanceTo(p) == 1
+}
+
+// Goal is where a path has to end: on Target itself, or if Adjacent is set on
+// any tile next to it (for walking up to an entity without standing on it)
+type Goal struct {
+	Target   Rect
+	Adjacent bool
+}
+
+// ReachedBy reports whether standing on p satisfies the goal
+func (g Goal) ReachedBy(p Point) bool {
+	if g.Adjacent {
+		return g.Target.IsNextTo(p)
+	}
+	return g.Target.Contains(p)
+}
+
+// anyWalkable reports whether any tile satisfying the goal can be stood on
+func (g Goal) anyWalkable(walkable func(x, y int) bool) bool {
+	if g.Adjacent {
+		for _, p := range g.Target.Perimeter() {
+			if walkable(p.X, p.Y) {
+				return true
+			}
+		}
+		return false
+	}
+	for y := g.Target.Min.Y; y < g.Target.Min.Y+g.Target.H; y++ {
+		for x := g.Target.Min.X; x < g.Target.Min.X+g.Target.W; x++ {
+			if walkable(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// estimate never overestimates the steps left from p
+func (g Goal) estimate(p Point) int {
+	d := g.Target.distanceTo(p)
+	if g.Adjacent && d > 0 {
+		d--
+	}
+	return d
+}
+
+// Result says how a search ended
+type Result int
+
+const (
+	Found      Result = iota
+	NoPath            // Nothing reachable, or the search hit its node limit
+	OverBudget        // Ran out of this tick's budget, try again next tick
+)
+
+var neighbourOffsets = [4]Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+type searchNode struct {
+	p      Point
+	g, f   int
+	seq    int // Push order, breaks ties so results don't depend on map order
+	parent *searchNode
+	index  int
+	closed bool
+}
+
+type openSet []*searchNode
+
+func (o openSet) Len() int { return len(o) }
+func (o openSet) Less(i, j int) bool {
+	if o[i].f != o[j].f {
+		return o[i].f < o[j].f
+	}
+	// prefer nodes further along, they're more likely to finish first
+	if o[i].g != o[j].g {
+		return o[i].g > o[j].g
+	}
+	return o[i].seq < o[j].seq
+}
+func (o openSet) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+	o[i].index = i
+	o[j].index = j
+}
+func (o *openSet) Push(x interface{}) {
+	n := x.(*searchNode)
+	n.index = len(*o)
+	*o = append(*o, n)
+}
+func (o *openSet) Pop() interface{} {
+	old := *o
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	*o = old[:len(old)-1]
+	return n
+}
+
+// search runs A* from start until it reaches goal or expands maxNodes tiles.
+// The start tile doesn't need to be walkable (the mover is standing on it),
+// but some tile of the goal does or there's no search at all.
+// It returns the path without start and how many nodes it expanded.
+func search(start Point, goal Goal, walkable func(x, y int) bool, maxNodes int) ([]Point, bool, int) {
+	if goal.ReachedBy(start) {
+		return []Point{}, true, 0
+	}
+	// a blocked goal would flood everything reachable before giving up
+	if !goal.anyWalkable(walkable) {
+		return nil, false, 0
+	}
+
+	nodes := make(map[Point]*searchNode)
+	open := &openSet{}
+	seq := 0
+
+	first := &searchNode{p: start, f: goal.estimate(start)}
+	nodes[start] = first
+	heap.Push(open, first)
+
+	expanded := 0
+	for open.Len() > 0 {
+		if expanded >= maxNodes {
+			return nil, false, expanded
+		}
+
+		current := heap.Pop(open).(*searchNode)
+		current.closed = true
+		expanded++
+
+		if goal.ReachedBy(current.p) {
+			return buildPath(current), true, expanded
+		}
+
+		for _, offset := range neighbourOffsets {
+			next := Point{current.p.X + offset.X, current.p.Y + offset.Y}
+			g := current.g + 1
+
+			node, seen := nodes[next]
+			if seen && (node.closed || g >= node.g) {
+				continue
+			}
+			if !seen {
+				if !walkable(next.X, next.Y) {
+					nodes[next] = &searchNode{p: next, closed: true}
+					continue
+				}
+				seq++
+				node = &searchNode{p: next, seq: seq}
+				nodes[next] = node
+			}
+
+			node.g = g
+			node.f = g + goal.estimate(next)
+			node.parent = current
+			if seen {
+				heap.Fix(open, node.index)
+			} else {
+				heap.Push(open, node)
+			}
+		}
+	}
+
+	return nil, false, expanded
+}
+
+func buildPath(end *searchNode) []Point {
+	length := 0
+	for n := end; n.parent != nil; n = n.parent {
+		length++
+	}
+
+	path := make([]Point, length)
+	for n := end; n.parent != nil; n = n.parent {
+		length--
+		path[length] = n.p
+	}
+	return path
+}
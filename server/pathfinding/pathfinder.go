@@ -0,0 +1,124 @@
+package pathfinding
+
+const (
+	DefaultTickBudget  = 20000 // Nodes a pathfinder may expand per tick
+	DefaultSearchLimit = 4096  // Nodes a single search may expand before giving up
+	DefaultCacheTicks  = 20    // Ticks a cached path is trusted for
+	maxCacheEntries    = 2048
+)
+
+type cacheKey struct {
+	start Point
+	goal  Goal
+}
+
+type cacheEntry struct {
+	path      []Point
+	found     bool
+	version   uint64 // Grid version it was searched against
+	expiresAt uint64 // Tick it stops being used
+}
+
+// Pathfinder finds paths over one grid with a per tick search budget, so a
+// crowd choosing targets at once can't stall a tick. Recent results are cached
+// and re-checked against the grid before being handed out again.
+//
+// It's not safe for concurrent use, each zone has its own and only uses it
+// from its own update.
+type Pathfinder struct {
+	Walkable    func(x, y int) bool // Whether a tile can be stood on right now
+	TickBudget  int
+	SearchLimit int
+	CacheTicks  uint64
+
+	tick    uint64
+	used    int    // Nodes expanded this tick
+	version uint64 // Bumped when obstacles change, invalidates the cache
+	cache   map[cacheKey]cacheEntry
+}
+
+func NewPathfinder(walkable func(x, y int) bool) *Pathfinder {
+	return &Pathfinder{
+		Walkable:    walkable,
+		TickBudget:  DefaultTickBudget,
+		SearchLimit: DefaultSearchLimit,
+		CacheTicks:  DefaultCacheTicks,
+		cache:       make(map[cacheKey]cacheEntry),
+	}
+}
+
+// BeginTick resets the search budget and drops expired cache entries
+func (p *Pathfinder) BeginTick(tick uint64) {
+	p.tick = tick
+	p.used = 0
+	for key, entry := range p.cache {
+		if entry.expiresAt <= tick || entry.version != p.version {
+			delete(p.cache, key)
+		}
+	}
+}
+
+// Invalidate forgets every cached path, call it when obstacles change
+func (p *Pathfinder) Invalidate() {
+	p.version++
+}
+
+// Remaining returns how many nodes can still be searched this tick
+func (p *Pathfinder) Remaining() int {
+	return max(p.TickBudget-p.used, 0)
+}
+
+// FindPath returns a path from start to goal, not including start. An empty
+// path means start already satisfies the goal. The path is the caller's to keep.
+func (p *Pathfinder) FindPath(start Point, goal Goal) ([]Point, Result) {
+	key := cacheKey{start: start, goal: goal}
+	if entry, ok := p.cache[key]; ok && entry.version == p.version && entry.expiresAt > p.tick {
+		if !entry.found {
+			return nil, NoPath
+		}
+		if p.isStillWalkable(entry.path) {
+			return append([]Point{}, entry.path...), Found
+		}
+		delete(p.cache, key)
+	}
+
+	limit := min(p.SearchLimit, p.Remaining())
+	if limit == 0 {
+		return nil, OverBudget
+	}
+
+	path, found, expanded := search(start, goal, p.Walkable, limit)
+	p.used += expanded
+
+	if !found && expanded >= limit && limit < p.SearchLimit {
+		// cut short by the tick budget rather than the search limit
+		return nil, OverBudget
+	}
+
+	p.store(key, path, found)
+	if !found {
+		return nil, NoPath
+	}
+	return append([]Point{}, path...), Found
+}
+
+func (p *Pathfinder) isStillWalkable(path []Point) bool {
+	for _, tile := range path {
+		if !p.Walkable(tile.X, tile.Y) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pathfinder) store(key cacheKey, path []Point, found bool) {
+	if len(p.cache) >= maxCacheEntries {
+		return
+	}
+	p.cache[key] = cacheEntry{
+		path:      path,
+		found:     found,
+		version:   p.version,
+		expiresAt: p.tick + p.CacheTicks,
+	}
+}
@@ -0,0 +1,73 @@
+package pathfinding
+
+import "testing"
+
+// openField is walkable everywhere except the given tiles
+func openField(blocked ...Point) func(x, y int) bool {
+	set := make(map[Point]bool, len(blocked))
+	for _, p := range blocked {
+		set[p] = true
+	}
+	return func(x, y int) bool {
+		return !set[Point{X: x, Y: y}]
+	}
+}
+
+func TestFindPathAroundObstacle(t *testing.T) {
+	p := NewPathfinder(openField(Point{1, 0}, Point{1, 1}, Point{1, -1}))
+	path, result := p.FindPath(Point{0, 0}, Goal{Target: PointRect(Point{2, 0})})
+	if result != Found {
+		t.Fatalf("got %v, want Found", result)
+	}
+	if len(path) != 6 || path[len(path)-1] != (Point{2, 0}) {
+		t.Fatalf("got path %v", path)
+	}
+}
+
+func TestFindPathToBlockedGoal(t *testing.T) {
+	target := Rect{Min: Point{10, 10}, W: 2, H: 2}
+
+	tests := []struct {
+		name    string
+		goal    Goal
+		blocked []Point
+	}{
+		{"blocked tile", Goal{Target: PointRect(Point{10, 10})}, []Point{{10, 10}}},
+		{"blocked rect", Goal{Target: target}, []Point{{10, 10}, {11, 10}, {10, 11}, {11, 11}}},
+		{"blocked perimeter", Goal{Target: target, Adjacent: true}, target.Perimeter()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPathfinder(openField(tt.blocked...))
+			p.BeginTick(1)
+
+			if _, result := p.FindPath(Point{0, 0}, tt.goal); result != NoPath {
+				t.Fatalf("got %v, want NoPath", result)
+			}
+			if p.Remaining() != p.TickBudget {
+				t.Fatalf("searched %d nodes for a goal nobody can stand on", p.TickBudget-p.Remaining())
+			}
+		})
+	}
+}
+
+// TestBlockedGoalsDontStarveOthers asks for lots of blocked goals in one tick,
+// an open one after them must still get searched
+func TestBlockedGoalsDontStarveOthers(t *testing.T) {
+	var blocked []Point
+	for i := 0; i < 20; i++ {
+		blocked = append(blocked, Point{100 + i, 100})
+	}
+	p := NewPathfinder(openField(blocked...))
+	p.BeginTick(1)
+
+	for _, tile := range blocked {
+		if _, result := p.FindPath(Point{0, 0}, Goal{Target: PointRect(tile)}); result != NoPath {
+			t.Fatalf("got %v for blocked %v, want NoPath", result, tile)
+		}
+	}
+	if _, result := p.FindPath(Point{0, 0}, Goal{Target: PointRect(Point{40, 0})}); result != Found {
+		t.Fatalf("got %v for an open goal, want Found", result)
+	}
+}
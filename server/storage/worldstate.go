@@ -0,0 +1,84 @@
+// world state persistence for shutdown
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"thereaalm/config"
+	"thereaalm/world"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const worldStateRedisKey = "world:state"
+
+// NewPersister returns the persister for the configured backend, or nil for "none"
+func NewPersister(cfg config.PersistenceConfig) (world.Persister, error) {
+	switch cfg.Backend {
+	case config.PersistenceNone:
+		return nil, nil
+	case config.PersistenceFile:
+		return &FilePersister{Path: cfg.FilePath}, nil
+	case config.PersistenceRedis:
+		return &RedisPersister{
+			Client: redis.NewClient(&redis.Options{Addr: cfg.RedisAddr}),
+			Key:    worldStateRedisKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown persistence backend %q", cfg.Backend)
+	}
+}
+
+// FilePersister writes the world state to a JSON file
+type FilePersister struct {
+	Path string
+}
+
+// SaveWorldState writes to a temp file first so a crash never leaves a half written save
+func (p *FilePersister) SaveWorldState(ctx context.Context, state *world.WorldState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err // out of time, keep the last good save
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p.Path), filepath.Base(p.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p.Path)
+}
+
+// RedisPersister stores the world state as JSON under a single key
+type RedisPersister struct {
+	Client *redis.Client
+	Key    string
+}
+
+func (p *RedisPersister) SaveWorldState(ctx context.Context, state *world.WorldState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return p.Client.Set(ctx, p.Key, data, 0).Err()
+}
@@ -1,7 +1,9 @@
 package taskpool
 
 import (
+	"context"
 	"log"
+	"sync"
 )
 
 // Task represents a unit of work for the pool
@@ -10,40 +12,86 @@ type Task func()
 // Pool manages a fixed number of worker goroutines
 type Pool struct {
 	tasks chan Task
-	done  chan struct{}
+
+	mu      sync.RWMutex // Guards closed against Submit
+	closed  bool
+	pending sync.WaitGroup // Submitted tasks that haven't finished
+	workers sync.WaitGroup
 }
 
 // NewPool initializes a new worker pool
 func NewPool(workerCount int) *Pool {
 	p := &Pool{
 		tasks: make(chan Task),
-		done:  make(chan struct{}),
 	}
 
+	p.workers.Add(workerCount)
 	for i := 0; i < workerCount; i++ {
 		go p.worker(i) // Start workers
 	}
 	return p
 }
 
-// Submit adds a new task to the pool
-func (p *Pool) Submit(task Task) {
-	select {
-	case p.tasks <- task:
-	case <-p.done:
+// Submit adds a new task to the pool. It returns false (and drops the task)
+// if the pool is shutting down.
+func (p *Pool) Submit(task Task) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
 		log.Println("Task rejected: pool is shutting down")
+		return false
+	}
+
+	p.pending.Add(1)
+	p.tasks <- task
+	return true
+}
+
+// Wait blocks until every task submitted so far has finished
+func (p *Pool) Wait() {
+	p.pending.Wait()
+}
+
+// Drain stops accepting new tasks, waits for submitted ones to finish and
+// then stops all workers. If ctx is done first the workers are left to finish
+// in the background and ctx's error is returned.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(p.tasks) // Terminate existing workers
+		p.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// Shutdown stops all workers
+// Shutdown stops all workers once submitted tasks have finished
 func (p *Pool) Shutdown() {
-	close(p.done)  // Stop accepting new tasks
-	close(p.tasks) // Terminate existing workers
+	p.Drain(context.Background())
 }
 
 func (p *Pool) worker(id int) {
+	defer p.workers.Done()
+
 	for task := range p.tasks {
 		task()
+		p.pending.Done()
 	}
 	log.Printf("Worker %d shutting down", id)
 }
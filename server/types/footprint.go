@@ -0,0 +1,34 @@
+package types
+
+import "thereaalm/pathfinding"
+
+// Footprint is the block of tiles an entity covers. Its position is the
+// anchor tile, which sits AnchorX/AnchorY tiles in from the top left corner.
+type Footprint struct {
+    W       int `json:"w"`
+    H       int `json:"h"`
+    AnchorX int `json:"anchorX"`
+    AnchorY int `json:"anchorY"`
+}
+
+// SingleTile is the footprint of anything that doesn't say otherwise
+var SingleTile = Footprint{W: 1, H: 1}
+
+// CenteredFootprint covers size x size tiles around the position
+func CenteredFootprint(size int) Footprint {
+    return Footprint{W: size, H: size, AnchorX: size / 2, AnchorY: size / 2}
+}
+
+// RectAt returns the tiles covered when the anchor is at x, y
+func (f Footprint) RectAt(x, y int) pathfinding.Rect {
+    return pathfinding.Rect{
+        Min: pathfinding.Point{X: x - f.AnchorX, Y: y - f.AnchorY},
+        W:   f.W,
+        H:   f.H,
+    }
+}
+
+// Reach is how far the footprint can stretch from its anchor in any direction
+func (f Footprint) Reach() int {
+    return max(f.AnchorX, f.W-1-f.AnchorX, f.AnchorY, f.H-1-f.AnchorY)
+}
@@ -6,4 +6,5 @@ type TargetSpec struct {
     TargetValue     interface{} // Optional parameter (e.g., "kekwood" for "has_item")
     SelfCriterion   string      // e.g., "min_pulse", "has_item"
     SelfValue       interface{} // e.g., 300 for "min_pulse"
+    PerceivedOnly   bool        // Only consider targets the actor can see
 }
\ No newline at end of file
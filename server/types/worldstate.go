@@ -0,0 +1,53 @@
+package types
+
+// WorldState is a set of facts an actor cares about when planning, e.g.
+// "has:kekwood" or "restored:altar". Missing facts count as false.
+type WorldState map[string]bool
+
+// HasItem is the fact for an actor carrying at least one of an item
+func HasItem(item string) string {
+    return "has:" + item
+}
+
+// Restored is the fact for an actor having restored some pulse to a target type
+func Restored(targetType string) string {
+    return "restored:" + targetType
+}
+
+// Rebuilt is the fact for an actor having rebuilt a target type
+func Rebuilt(targetType string) string {
+    return "rebuilt:" + targetType
+}
+
+// Satisfies is true if every one of conditions holds in s
+func (s WorldState) Satisfies(conditions WorldState) bool {
+    for fact, want := range conditions {
+        if s[fact] != want {
+            return false
+        }
+    }
+    return true
+}
+
+// Unsatisfied counts how many of conditions don't hold in s
+func (s WorldState) Unsatisfied(conditions WorldState) int {
+    n := 0
+    for fact, want := range conditions {
+        if s[fact] != want {
+            n++
+        }
+    }
+    return n
+}
+
+// Apply returns a copy of s with effects applied, s is left alone
+func (s WorldState) Apply(effects WorldState) WorldState {
+    next := make(WorldState, len(s)+len(effects))
+    for fact, value := range s {
+        next[fact] = value
+    }
+    for fact, value := range effects {
+        next[fact] = value
+    }
+    return next
+}
@@ -12,7 +12,7 @@ func UpdateBuffMultiplier(consumer interfaces.IBuffConsumer, maxRange int) {
         return
     }
     x, y := consumer.GetPosition()
-    nearbyEntities := zone.FindNearbyEntities(x, y, maxRange) // Max Altar range
+    nearbyEntities := zone.FindEntitiesInRadius(x, y, maxRange, interfaces.Manhattan) // Max Altar range
     maxBuff := 1.0
     for _, entity := range nearbyEntities {
         if buffProvider, ok := entity.(interfaces.IBuffProvider); ok {
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// DeriveSeed mixes a parent seed with a stream id (splitmix64) so that every
+// zone and entity gets its own independent, reproducible random stream
+func DeriveSeed(seed int64, stream int64) int64 {
+	z := uint64(seed) + uint64(stream)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return int64(z ^ (z >> 31))
+}
+
+// NewRand creates a new random source from a seed
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewUUID draws a version 4 uuid from r, so entity ids come from the seed
+// like everything else rather than crypto/rand
+func NewUUID(r *rand.Rand) uuid.UUID {
+	var id uuid.UUID
+	r.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40 // version 4
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+	return id
+}
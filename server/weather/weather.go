@@ -0,0 +1,157 @@
+package weather
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Kind is the type of weather a zone is having
+type Kind string
+
+const (
+	Clear Kind = "clear"
+	Rain  Kind = "rain"
+	Storm Kind = "storm"
+	Heat  Kind = "heat"
+	Fog   Kind = "fog"
+)
+
+// kinds is the order kinds are rolled in, map order would break determinism
+var kinds = []Kind{Clear, Rain, Storm, Heat, Fog}
+
+// Effects are gameplay multipliers, 1 means no change
+type Effects struct {
+	ActionDuration float64 // How long gathering actions take
+	Regrow         float64 // How fast resources grow back
+	Drain          float64 // How fast actions drain ESP (ecto, spark, pulse)
+	Visibility     float64 // How far entities can see
+}
+
+var NoEffects = Effects{ActionDuration: 1, Regrow: 1, Drain: 1, Visibility: 1}
+
+// KindEffects are each kind's effects at full intensity
+var KindEffects = map[Kind]Effects{
+	Clear: NoEffects,
+	Rain:  {ActionDuration: 1.1, Regrow: 1.5, Drain: 1.0, Visibility: 0.8},
+	Storm: {ActionDuration: 1.5, Regrow: 1.2, Drain: 1.5, Visibility: 0.6},
+	Heat:  {ActionDuration: 1.2, Regrow: 0.5, Drain: 1.5, Visibility: 0.9},
+	Fog:   {ActionDuration: 1.1, Regrow: 1.0, Drain: 1.0, Visibility: 0.4},
+}
+
+// State is a zone's weather at a moment in time
+type State struct {
+	Kind      Kind    `json:"kind"`
+	Intensity float64 `json:"intensity"` // 0-1, ramps up as a spell starts and down as it ends
+}
+
+// Effects scales the kind's effects by intensity
+func (s State) Effects() Effects {
+	full, ok := KindEffects[s.Kind]
+	if !ok {
+		return NoEffects
+	}
+	lerp := func(v float64) float64 { return 1 + (v-1)*s.Intensity }
+	return Effects{
+		ActionDuration: lerp(full.ActionDuration),
+		Regrow:         lerp(full.Regrow),
+		Drain:          lerp(full.Drain),
+		Visibility:     lerp(full.Visibility),
+	}
+}
+
+// RampTime is how long weather takes to build up and die away
+const RampTime = 2 * time.Minute
+
+// Spell is a stretch of one kind of weather
+type Spell struct {
+	Kind  Kind
+	Start time.Duration
+	End   time.Duration
+}
+
+// StateAt returns the weather at a game time within the spell
+func (s Spell) StateAt(now time.Duration) State {
+	ramp := RampTime
+	if half := (s.End - s.Start) / 2; half < ramp {
+		ramp = half
+	}
+
+	intensity := 1.0
+	if ramp > 0 {
+		in := float64(now-s.Start) / float64(ramp)
+		out := float64(s.End-now) / float64(ramp)
+		intensity = math.Max(0, math.Min(1, math.Min(in, out)))
+	}
+	return State{Kind: s.Kind, Intensity: intensity}
+}
+
+// Climate is how a biome's weather behaves
+type Climate struct {
+	Odds        map[Kind]float64 // Relative chance of each kind when the weather changes
+	MinDuration time.Duration    // Shortest spell
+	MaxDuration time.Duration    // Longest spell
+}
+
+var DefaultClimate = Climate{
+	Odds:        map[Kind]float64{Clear: 6, Rain: 2, Fog: 1, Storm: 0.5, Heat: 0.5},
+	MinDuration: 5 * time.Minute,
+	MaxDuration: 20 * time.Minute,
+}
+
+// BiomeClimates holds per-biome overrides of DefaultClimate, keyed by the
+// biome names in config.ZoneMap
+var BiomeClimates = map[string]Climate{
+	"defi_desert":         {Odds: map[Kind]float64{Clear: 4, Heat: 5, Storm: 0.5}, MinDuration: 10 * time.Minute, MaxDuration: 30 * time.Minute},
+	"maagma_springs":      {Odds: map[Kind]float64{Clear: 3, Heat: 6, Fog: 1}, MinDuration: 10 * time.Minute, MaxDuration: 30 * time.Minute},
+	"daark_forest":        {Odds: map[Kind]float64{Clear: 3, Fog: 4, Rain: 3}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"tree_of_fud":         {Odds: map[Kind]float64{Clear: 3, Fog: 4, Rain: 2, Storm: 1}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"laughing_peaks":      {Odds: map[Kind]float64{Clear: 4, Storm: 3, Fog: 2}, MinDuration: 3 * time.Minute, MaxDuration: 15 * time.Minute},
+	"the_infinity_cliffs": {Odds: map[Kind]float64{Clear: 4, Storm: 3, Fog: 2}, MinDuration: 3 * time.Minute, MaxDuration: 15 * time.Minute},
+	"rofl_reef":           {Odds: map[Kind]float64{Clear: 5, Rain: 3, Storm: 2}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"north_beach":         {Odds: map[Kind]float64{Clear: 5, Rain: 2, Storm: 2, Heat: 1}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"south_beach":         {Odds: map[Kind]float64{Clear: 5, Rain: 2, Storm: 1, Heat: 2}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"poly_lakes":          {Odds: map[Kind]float64{Clear: 4, Rain: 3, Fog: 3}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"yield_fields":        {Odds: map[Kind]float64{Clear: 6, Rain: 3, Heat: 1}, MinDuration: 5 * time.Minute, MaxDuration: 20 * time.Minute},
+	"caaverns":            {Odds: map[Kind]float64{Clear: 8, Fog: 1}, MinDuration: 10 * time.Minute, MaxDuration: 40 * time.Minute},
+}
+
+// ClimateFor returns a biome's climate, or DefaultClimate if it has none
+func ClimateFor(biome string) Climate {
+	if climate, ok := BiomeClimates[biome]; ok {
+		return climate
+	}
+	return DefaultClimate
+}
+
+// NextSpell rolls the spell that follows prev. The same kind is rolled at
+// half odds so weather doesn't get stuck.
+func (c Climate) NextSpell(r *rand.Rand, start time.Duration, prev Kind) Spell {
+	total := 0.0
+	weight := func(kind Kind) float64 {
+		w := c.Odds[kind]
+		if kind == prev {
+			w /= 2
+		}
+		return w
+	}
+	for _, kind := range kinds {
+		total += weight(kind)
+	}
+
+	kind := Clear
+	roll := r.Float64() * total
+	for _, k := range kinds {
+		if roll < weight(k) {
+			kind = k
+			break
+		}
+		roll -= weight(k)
+	}
+
+	duration := c.MinDuration
+	if c.MaxDuration > c.MinDuration {
+		duration += time.Duration(r.Int63n(int64(c.MaxDuration - c.MinDuration)))
+	}
+	return Spell{Kind: kind, Start: start, End: start + duration}
+}
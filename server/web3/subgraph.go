@@ -19,6 +19,9 @@ type SubgraphGotchiData struct {
 }
 
 var (
+	// SubgraphURL is the aavegotchi subgraph we query, set from the server config
+	SubgraphURL = "https://subgraph.satsuma-prod.com/tWYl5n5y04oz/aavegotchi/aavegotchi-core-matic/api"
+
 	DefaultSubgraphGotchiData = SubgraphGotchiData{
 		ID: "69420",
 		Name: "Default",
@@ -50,7 +53,7 @@ func FetchGotchisBatch(first, skip int) (map[string]SubgraphGotchiData, error) {
 	}
 
 	resp, err := http.Post(
-		"https://subgraph.satsuma-prod.com/tWYl5n5y04oz/aavegotchi/aavegotchi-core-matic/api",
+		SubgraphURL,
 		"application/json",
 		bytes.NewBuffer(payloadBytes),
 	)
@@ -117,7 +120,7 @@ func FetchGotchisByIDs(ids []string) (map[string]SubgraphGotchiData, error) {
 	}
 
 	resp, err := http.Post(
-		"https://subgraph.satsuma-prod.com/tWYl5n5y04oz/aavegotchi/aavegotchi-core-matic/api",
+		SubgraphURL,
 		"application/json",
 		bytes.NewBuffer(payloadBytes),
 	)
@@ -0,0 +1,277 @@
+package world
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"thereaalm/interfaces"
+	"thereaalm/stattypes"
+
+	"github.com/google/uuid"
+)
+
+// Command is an external mutation (API request, admin tool...) of the world.
+// Commands are queued and applied in submission order on the simulation
+// goroutine at the start of the next tick, so nothing outside the tick ever
+// touches live entity state.
+type Command interface {
+	Apply(wm *WorldManager) (interface{}, error)
+}
+
+// CommandResult is what a command's Apply returned
+type CommandResult struct {
+	Value interface{}
+	Err   error
+}
+
+type queuedCommand struct {
+	cmd    Command
+	result chan CommandResult
+	state  atomic.Int32 // commandPending until the tick claims it or the caller gives up
+}
+
+const (
+	commandPending int32 = iota
+	commandApplied
+	commandCancelled
+)
+
+var (
+	ErrZoneNotFound   = errors.New("zone not found")
+	ErrEntityNotFound = errors.New("entity not found")
+	ErrInvalidCommand = errors.New("invalid command")
+	ErrShuttingDown   = errors.New("world is shutting down")
+)
+
+// Submit queues a command for the next tick. The returned channel receives
+// exactly one result once the command has been applied, or ErrShuttingDown.
+func (wm *WorldManager) Submit(cmd Command) <-chan CommandResult {
+	return wm.submit(cmd).result
+}
+
+func (wm *WorldManager) submit(cmd Command) *queuedCommand {
+	qc := &queuedCommand{cmd: cmd, result: make(chan CommandResult, 1)}
+
+	wm.commandMu.Lock()
+	defer wm.commandMu.Unlock()
+
+	if wm.commandsClosed {
+		qc.result <- CommandResult{Err: ErrShuttingDown}
+		return qc
+	}
+	wm.commandQueue = append(wm.commandQueue, qc)
+
+	return qc
+}
+
+// closeCommands rejects new commands and fails any that haven't been applied
+func (wm *WorldManager) closeCommands() {
+	wm.commandMu.Lock()
+	queue := wm.commandQueue
+	wm.commandQueue = nil
+	wm.commandsClosed = true
+	wm.commandMu.Unlock()
+
+	for _, qc := range queue {
+		qc.result <- CommandResult{Err: ErrShuttingDown}
+	}
+}
+
+// SubmitAndWait queues a command and blocks until it has been applied or ctx
+// is done. A command that times out is cancelled and never applied, unless
+// the tick had already started applying it, in which case we wait for its
+// result so the caller isn't told it failed when it didn't.
+func (wm *WorldManager) SubmitAndWait(ctx context.Context, cmd Command) (interface{}, error) {
+	qc := wm.submit(cmd)
+	select {
+	case res := <-qc.result:
+		return res.Value, res.Err
+	case <-ctx.Done():
+		if qc.state.CompareAndSwap(commandPending, commandCancelled) {
+			return nil, ctx.Err()
+		}
+		res := <-qc.result
+		return res.Value, res.Err
+	}
+}
+
+// applyCommands drains the queue, it must only be called from Step()
+func (wm *WorldManager) applyCommands() {
+	wm.commandMu.Lock()
+	queue := wm.commandQueue
+	wm.commandQueue = nil
+	wm.commandMu.Unlock()
+
+	for _, qc := range queue {
+		if !qc.state.CompareAndSwap(commandPending, commandApplied) {
+			continue // the caller gave up waiting
+		}
+		value, err := qc.cmd.Apply(wm)
+		qc.result <- CommandResult{Value: value, Err: err}
+	}
+}
+
+// GetZoneByID returns the zone with the given id or nil if there isn't one
+func (wm *WorldManager) GetZoneByID(zoneID int) interfaces.IZone {
+	if zoneID < 0 || zoneID >= len(wm.Zones) {
+		return nil
+	}
+	return wm.Zones[zoneID]
+}
+
+// findEntity looks up an entity in any zone by uuid, or by gotchi token id if
+// the uuid isn't set
+func (wm *WorldManager) findEntity(id uuid.UUID, gotchiID string) (interfaces.IEntity, error) {
+	switch {
+	case id != uuid.Nil:
+		if entity := wm.GetEntityByUUID(id); entity != nil {
+			return entity, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, id)
+	case gotchiID != "":
+		if entity := wm.GetGotchiByTokenID(gotchiID); entity != nil {
+			return entity, nil
+		}
+		return nil, fmt.Errorf("%w: gotchi %s", ErrEntityNotFound, gotchiID)
+	default:
+		return nil, fmt.Errorf("%w: a uuid or gotchi id is required", ErrInvalidCommand)
+	}
+}
+
+// findStatsEntity looks up an entity with stats like findEntity
+func (wm *WorldManager) findStatsEntity(id uuid.UUID, gotchiID string) (interfaces.IStats, error) {
+	entity, err := wm.findEntity(id, gotchiID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, ok := entity.(interfaces.IStats)
+	if !ok {
+		return nil, fmt.Errorf("%w: entity %s has no stats", ErrInvalidCommand, entity.GetUUID())
+	}
+	return stats, nil
+}
+
+// LookupEntityCommand snapshots an entity (and the zone it's in) by uuid, or
+// a gotchi by token id
+type LookupEntityCommand struct {
+	UUID     uuid.UUID
+	GotchiID string // Token id, used if UUID isn't set
+}
+
+func (c LookupEntityCommand) Apply(wm *WorldManager) (interface{}, error) {
+	entity, err := wm.findEntity(c.UUID, c.GotchiID)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := entity.GetZone()
+	if zone == nil {
+		return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, entity.GetUUID())
+	}
+	return newEntitySnapshot(zone.GetID(), entity), nil
+}
+
+// GotchiStatsResult is a copy of a gotchi's stats taken when a command was applied
+type GotchiStatsResult struct {
+	TreatTotal float64
+	StakedGhst float64
+	Ecto       float64
+	Spark      float64
+	Pulse      float64
+}
+
+func newGotchiStatsResult(stats interfaces.IStats) GotchiStatsResult {
+	return GotchiStatsResult{
+		TreatTotal: stats.GetStat(stattypes.TreatTotal),
+		StakedGhst: stats.GetStat(stattypes.StakedGHST),
+		Ecto:       stats.GetStat(stattypes.Ecto),
+		Spark:      stats.GetStat(stattypes.Spark),
+		Pulse:      stats.GetStat(stattypes.Pulse),
+	}
+}
+
+// StakeGHSTCommand stakes GHST on a gotchi
+type StakeGHSTCommand struct {
+	UUID     uuid.UUID
+	GotchiID string // Token id, used if UUID isn't set
+	Amount   int
+}
+
+func (c StakeGHSTCommand) Apply(wm *WorldManager) (interface{}, error) {
+	if c.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be a positive number", ErrInvalidCommand)
+	}
+
+	stats, err := wm.findStatsEntity(c.UUID, c.GotchiID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.DeltaStat(stattypes.StakedGHST, float64(c.Amount))
+	return newGotchiStatsResult(stats), nil
+}
+
+// UnstakeGHSTCommand unstakes GHST from a gotchi (never below zero)
+type UnstakeGHSTCommand struct {
+	UUID     uuid.UUID
+	GotchiID string // Token id, used if UUID isn't set
+	Amount   int
+}
+
+func (c UnstakeGHSTCommand) Apply(wm *WorldManager) (interface{}, error) {
+	if c.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be a positive number", ErrInvalidCommand)
+	}
+
+	stats, err := wm.findStatsEntity(c.UUID, c.GotchiID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.DeltaStat(stattypes.StakedGHST, float64(-c.Amount))
+	if stats.GetStat(stattypes.StakedGHST) < 0 {
+		stats.SetStat(stattypes.StakedGHST, 0)
+	}
+	return newGotchiStatsResult(stats), nil
+}
+
+// TreatCosts is how much treat each treat type costs to eat
+var TreatCosts = map[string]int{
+	"Sushi Roll": 500,
+	"Coconut":    500,
+	"Candy":      500,
+}
+
+// EatTreatCommand feeds a gotchi a treat, restoring one of its ESP stats
+type EatTreatCommand struct {
+	UUID      uuid.UUID
+	GotchiID  string // Token id, used if UUID isn't set
+	TreatName string
+}
+
+func (c EatTreatCommand) Apply(wm *WorldManager) (interface{}, error) {
+	cost, ok := TreatCosts[c.TreatName]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid treat name %q", ErrInvalidCommand, c.TreatName)
+	}
+
+	stats, err := wm.findStatsEntity(c.UUID, c.GotchiID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.TreatName == "Sushi Roll" {
+		stats.DeltaStat(stattypes.Ecto, 100)
+	} else if c.TreatName == "Coconut" {
+		stats.DeltaStat(stattypes.Spark, 100)
+	} else if c.TreatName == "Candy" {
+		stats.DeltaStat(stattypes.Pulse, 100)
+	}
+
+	// remove some treat
+	stats.DeltaStat(stattypes.TreatTotal, -float64(cost))
+
+	return newGotchiStatsResult(stats), nil
+}
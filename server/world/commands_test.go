@@ -0,0 +1,133 @@
+package world
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"thereaalm/stattypes"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func firstGotchiID(t *testing.T, wm *WorldManager) uuid.UUID {
+	t.Helper()
+	for _, e := range wm.Zones[42].(*Zone).GetEntitiesByType("gotchi") {
+		return e.GetUUID()
+	}
+	t.Fatal("no gotchi in zone 42")
+	return uuid.Nil
+}
+
+func stakedGHST(t *testing.T, wm *WorldManager, id uuid.UUID) float64 {
+	t.Helper()
+	stats, err := wm.findStatsEntity(id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stats.GetStat(stattypes.StakedGHST)
+}
+
+// TestCommandsUnderLoad submits commands from several goroutines while the
+// world steps, run it with -race. Every stake that reported success must
+// have been applied exactly once, and nothing that timed out may have been.
+func TestCommandsUnderLoad(t *testing.T) {
+	wm := newTestWorld(t, 4, 7)
+	id := firstGotchiID(t, wm)
+
+	const submitters = 8
+	var applied atomic.Int64 // GHST staked by commands that said they worked
+	var timedOut atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < submitters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				cmd := StakeGHSTCommand{UUID: id, Amount: 1}
+				switch n % 3 {
+				case 0:
+					// fire and forget, it's still applied exactly once
+					res := <-wm.Submit(cmd)
+					if res.Err != nil {
+						t.Error(res.Err)
+						return
+					}
+					applied.Add(1)
+				case 1:
+					// anything from no time at all to a few ticks, some time out
+					ctx, cancel := context.WithTimeout(context.Background(), time.Duration(n%5)*time.Millisecond)
+					_, err := wm.SubmitAndWait(ctx, cmd)
+					cancel()
+					switch {
+					case err == nil:
+						applied.Add(1)
+					case errors.Is(err, context.DeadlineExceeded):
+						timedOut.Add(1)
+					default:
+						t.Error(err)
+						return
+					}
+				case 2:
+					if _, err := wm.SubmitAndWait(context.Background(), LookupEntityCommand{UUID: id}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(i)
+	}
+
+	for tick := 0; tick < 100; tick++ {
+		wm.StepN(1)
+	}
+	close(stop)
+
+	// keep stepping until every submitter has had its last command applied
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		default:
+			wm.StepN(1)
+		}
+	}
+	wm.StepN(1)
+	t.Logf("%d stakes applied, %d timed out", applied.Load(), timedOut.Load())
+
+	if got, want := stakedGHST(t, wm, id), float64(applied.Load()); got != want {
+		t.Fatalf("staked %v GHST, but %v stakes reported success", got, want)
+	}
+}
+
+func TestTimedOutCommandIsNotApplied(t *testing.T) {
+	wm := newTestWorld(t, 2, 7)
+	id := firstGotchiID(t, wm)
+	before := stakedGHST(t, wm, id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := wm.SubmitAndWait(ctx, StakeGHSTCommand{UUID: id, Amount: 10}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	wm.StepN(2)
+	if got := stakedGHST(t, wm, id); got != before {
+		t.Fatalf("cancelled stake was applied: staked %v, was %v", got, before)
+	}
+}
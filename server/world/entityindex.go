@@ -0,0 +1,96 @@
+package world
+
+import (
+	"sync"
+	"thereaalm/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// entityIndex finds any entity in the world by uuid, or a gotchi by its token
+// id, without knowing its zone. Zones keep it up to date as entities are added
+// and removed (migrating between zones doesn't touch it), which can happen
+// from several zone workers at once.
+type entityIndex struct {
+	mu         sync.RWMutex
+	byUUID     map[uuid.UUID]interfaces.IEntity
+	byGotchiID map[string][]interfaces.IEntity // Test scenarios reuse token ids
+}
+
+func newEntityIndex() *entityIndex {
+	return &entityIndex{
+		byUUID:     make(map[uuid.UUID]interfaces.IEntity),
+		byGotchiID: make(map[string][]interfaces.IEntity),
+	}
+}
+
+func (idx *entityIndex) add(e interfaces.IEntity) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, exists := idx.byUUID[e.GetUUID()]; exists {
+		return
+	}
+	idx.byUUID[e.GetUUID()] = e
+
+	if gotchi, ok := e.(interfaces.IGotchi); ok {
+		tokenID := gotchi.GetGotchiID()
+		idx.byGotchiID[tokenID] = append(idx.byGotchiID[tokenID], e)
+	}
+}
+
+func (idx *entityIndex) remove(e interfaces.IEntity) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, exists := idx.byUUID[e.GetUUID()]; !exists {
+		return
+	}
+	delete(idx.byUUID, e.GetUUID())
+
+	if gotchi, ok := e.(interfaces.IGotchi); ok {
+		tokenID := gotchi.GetGotchiID()
+		gotchis := idx.byGotchiID[tokenID]
+		for i, g := range gotchis {
+			if g.GetUUID() == e.GetUUID() {
+				gotchis = append(gotchis[:i], gotchis[i+1:]...)
+				break
+			}
+		}
+		if len(gotchis) == 0 {
+			delete(idx.byGotchiID, tokenID)
+		} else {
+			idx.byGotchiID[tokenID] = gotchis
+		}
+	}
+}
+
+func (idx *entityIndex) get(id uuid.UUID) interfaces.IEntity {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.byUUID[id]
+}
+
+// getGotchi returns the gotchi with a token id. If several share it the one
+// added first wins.
+func (idx *entityIndex) getGotchi(tokenID string) interfaces.IEntity {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if gotchis := idx.byGotchiID[tokenID]; len(gotchis) > 0 {
+		return gotchis[0]
+	}
+	return nil
+}
+
+// GetEntityByUUID returns the entity with the given uuid from any zone, or nil
+func (wm *WorldManager) GetEntityByUUID(id uuid.UUID) interfaces.IEntity {
+	return wm.entities.get(id)
+}
+
+// GetGotchiByTokenID returns the gotchi with the given aavegotchi token id
+// from any zone, or nil
+func (wm *WorldManager) GetGotchiByTokenID(tokenID string) interfaces.IEntity {
+	return wm.entities.getGotchi(tokenID)
+}
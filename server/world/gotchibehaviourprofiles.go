@@ -1,6 +1,7 @@
 package world
 
 import (
+	"thereaalm/action"
 	"thereaalm/action/buildingactions"
 	"thereaalm/action/combatactions"
 	"thereaalm/action/explorationactions"
@@ -254,6 +255,18 @@ var BuilderProfile GotchiBehaviorProfile = func(g *entity.Gotchi) {
                 TargetType: "altar",
                 TargetCriterion: "nearest",
             }))
+
+	// builder goals, fetching the materials first if need be
+    g.AddGoalToPlan(action.Goal{
+        Name: "restore altar",
+        Conditions: types.WorldState{types.Restored("altar"): true},
+        Weighting: 1,
+    })
+    g.AddGoalToPlan(action.Goal{
+        Name: "rebuild altar",
+        Conditions: types.WorldState{types.Rebuilt("altar"): true},
+        Weighting: 1,
+    })
     
 
 
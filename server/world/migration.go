@@ -0,0 +1,91 @@
+package world
+
+import (
+	"log"
+	"thereaalm/interfaces"
+)
+
+// Zones are updated in four phases laid out like a 2x2 checkerboard over
+// config.ZoneMap, so no two zones that touch (even diagonally) ever update at
+// the same time. That lets an entity near an edge read and act on the
+// neighbouring zone without races, and keeps results independent of the
+// worker count.
+const zonePhaseCount = 4
+
+func (wm *WorldManager) buildZonePhases() {
+	for _, z := range wm.Zones {
+		zone, ok := z.(*Zone)
+		if !ok {
+			continue
+		}
+		gridX := zone.X / ZoneTiles
+		gridY := zone.Y / ZoneTiles
+		phase := (gridY%2)*2 + gridX%2
+		wm.zonePhases[phase] = append(wm.zonePhases[phase], zone)
+	}
+}
+
+// updateZonePhase runs every zone in a phase across the worker pool
+func (wm *WorldManager) updateZonePhase(zones []*Zone, dt_s float64) {
+	if len(zones) == 0 {
+		return
+	}
+
+	for _, zone := range zones {
+		zone.Updating = true
+	}
+	wm.inPhase = true
+
+	wm.forEachZone(zones, func(zone *Zone) {
+		zone.Update(dt_s)
+	})
+
+	wm.inPhase = false
+	for _, zone := range zones {
+		zone.Updating = false
+	}
+}
+
+// flushPendingZoneChanges applies adds/removals that neighbouring zones made
+// to zones outside the phase that just ran
+func (wm *WorldManager) flushPendingZoneChanges() {
+	for _, z := range wm.Zones {
+		if zone, ok := z.(*Zone); ok {
+			zone.flushPendingChanges()
+		}
+	}
+}
+
+// migrateEntities hands any entity that has walked past its zone's bounds to
+// the zone it's now standing in. Zones and entities are visited in order so
+// the handoff is deterministic.
+func (wm *WorldManager) migrateEntities() {
+	for _, z := range wm.Zones {
+		zone, ok := z.(*Zone)
+		if !ok {
+			continue
+		}
+
+		var leaving []interfaces.IEntity
+		for _, e := range zone.Entities {
+			x, y := e.GetPosition()
+			if !zone.isPositionWithinZone(x, y) {
+				leaving = append(leaving, e)
+			}
+		}
+
+		for _, e := range leaving {
+			x, y := e.GetPosition()
+			newZone := wm.getZoneForPosition(x, y)
+			if newZone == nil {
+				log.Printf("WARNING: %s %s left zone %d into the void at (%d, %d)",
+					e.GetType(), e.GetUUID(), zone.GetID(), x, y)
+				continue
+			}
+
+			zone.removeEntityNow(e)
+			newZone.(*Zone).addEntityNow(e)
+			zone.Scheduler.transferOwner(e.GetUUID(), newZone.(*Zone).Scheduler)
+		}
+	}
+}
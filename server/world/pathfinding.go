@@ -0,0 +1,36 @@
+package world
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+)
+
+// pathZoneMargin is how far past the zone's edge a path may go. It has to stay
+// well under a zone's size, neighbours never update at the same time as us
+// but their neighbours can.
+const pathZoneMargin = 32
+
+// isTileWalkable is what the zone's pathfinder walks on: tiles with no
+// obstacle or entity on them, in this zone or just over the edge of it
+func (z *Zone) isTileWalkable(x, y int) bool {
+	if !z.isPositionWithinZone(x, y) {
+		return x >= z.X-pathZoneMargin && y >= z.Y-pathZoneMargin &&
+			x < z.X+z.Width+pathZoneMargin && y < z.Y+z.Height+pathZoneMargin &&
+			z.IsPositionAvailable(x, y)
+	}
+	return !z.IsObstacle(x, y) && z.SpatialMap.IsPositionAvailable(x, y)
+}
+
+// FindPath finds a path from a tile to a goal, see pathfinding.Pathfinder.
+// Only call it from this zone's update.
+func (z *Zone) FindPath(fromX, fromY int, goal pathfinding.Goal) ([]pathfinding.Point, pathfinding.Result) {
+	return z.Pathfinder.FindPath(pathfinding.Point{X: fromX, Y: fromY}, goal)
+}
+
+// FindPathToEntity finds a path to a free tile next to target's footprint
+func (z *Zone) FindPathToEntity(fromX, fromY int, target interfaces.IEntity) ([]pathfinding.Point, pathfinding.Result) {
+	return z.FindPath(fromX, fromY, pathfinding.Goal{
+		Target:   target.GetBounds(),
+		Adjacent: true,
+	})
+}
@@ -0,0 +1,106 @@
+package world
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/utils"
+)
+
+// DefaultVisionRadius is how far entities without eyesight of their own
+// (see interfaces.IPerceiver) see in clear daylight
+const DefaultVisionRadius = 12.0
+
+// minVisionRadius is how far anything can see, even at night in thick fog
+const minVisionRadius = 2
+
+// GetVisionRadius returns how far (in tiles, as the crow flies) an entity can
+// see right now: its own eyesight scaled down at night and in bad weather
+func (z *Zone) GetVisionRadius(observer interfaces.IEntity) int {
+	radius := DefaultVisionRadius
+	if perceiver, ok := observer.(interfaces.IPerceiver); ok {
+		radius = perceiver.GetBaseVisionRadius()
+	}
+	radius *= z.WorldManager.GetDate().VisionRadius() * z.GetWeather().Effects().Visibility
+	return max(int(radius), minVisionRadius)
+}
+
+// HasLineOfSight checks no obstacle sits on the straight line between two
+// tiles. The end tiles themselves don't count, you can see a boulder you're
+// standing next to.
+func (z *Zone) HasLineOfSight(x0, y0, x1, y1 int) bool {
+	if x0 == x1 && y0 == y1 {
+		return true
+	}
+
+	dx, dy := utils.Abs(x1-x0), -utils.Abs(y1-y0)
+	sx, sy := 1, 1
+	if x1 < x0 {
+		sx = -1
+	}
+	if y1 < y0 {
+		sy = -1
+	}
+
+	// bresenham
+	err := dx + dy
+	x, y := x0, y0
+	for {
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+		if x == x1 && y == y1 {
+			return true
+		}
+		if z.IsObstacle(x, y) {
+			return false
+		}
+	}
+}
+
+// CanPerceive checks a target is within the observer's vision radius and not
+// hidden behind obstacles. Big targets only need their closest tile in view.
+func (z *Zone) CanPerceive(observer, target interfaces.IEntity) bool {
+	return z.canPerceiveWithin(observer, target, z.GetVisionRadius(observer))
+}
+
+func (z *Zone) canPerceiveWithin(observer, target interfaces.IEntity, radius int) bool {
+	ox, oy := observer.GetPosition()
+	if target.GetBounds().Contains(pathfinding.Point{X: ox, Y: oy}) {
+		return true
+	}
+
+	closest := target.GetBounds().ClosestTo(pathfinding.Point{X: ox, Y: oy})
+	dx, dy := closest.X-ox, closest.Y-oy
+	if dx*dx+dy*dy > radius*radius {
+		return false
+	}
+	return z.HasLineOfSight(ox, oy, closest.X, closest.Y)
+}
+
+// FindPerceivedEntities finds the entities of a type ("" for any) in this
+// zone the observer can see, in the same order as FindEntitiesInRadius
+func (z *Zone) FindPerceivedEntities(observer interfaces.IEntity, entityType string) []interfaces.IEntity {
+	radius := z.GetVisionRadius(observer)
+	ox, oy := observer.GetPosition()
+
+	// a big entity's anchor can be out of range while its edge is in view
+	searchRadius := radius + 2*z.SpatialMap.footprintReach
+
+	var perceived []interfaces.IEntity
+	z.SpatialMap.ForEachInRadius(ox, oy, searchRadius, interfaces.Euclidean, func(e interfaces.IEntity) bool {
+		if e.GetUUID() == observer.GetUUID() || (entityType != "" && e.GetType() != entityType) {
+			return true
+		}
+		if z.canPerceiveWithin(observer, e, radius) {
+			perceived = append(perceived, e)
+		}
+		return true
+	})
+	return perceived
+}
@@ -0,0 +1,64 @@
+package world
+
+import (
+	"testing"
+	"thereaalm/action/actiontargeting"
+	"thereaalm/utils"
+	"thereaalm/web3"
+)
+
+// TestHuntersDontPathToWhatTheyCantSee puts gotchis in a lickquidator's
+// search range but out of its sight. Hunting by sight must skip them without
+// spending any pathfinding on them, then find one that steps into view.
+func TestHuntersDontPathToWhatTheyCantSee(t *testing.T) {
+	wm := NewWorldManager(1, 1, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+	zx, zy := zone.GetPosition()
+	cx, cy := zx+ZoneTiles/2, zy+ZoneTiles/2
+
+	x, y, found := wm.FindNearbyAvailablePosition(wm.Rand, cx, cy, 8, 1)
+	if !found {
+		t.Fatal("no room for the lickquidator")
+	}
+	lickquidator := generateGenericLickquidator(wm, utils.NewUUID(wm.Rand), x, y)
+	hunt := lickquidator.Actions[0]
+	if spec := hunt.GetFallbackTargetSpec(); spec.TargetType != "gotchi" || !spec.PerceivedOnly {
+		t.Fatalf("first lickquidator action hunts %q by sight=%v", spec.TargetType, spec.PerceivedOnly)
+	}
+
+	// diagonally out past the vision radius, but well inside twice it
+	vision := zone.GetVisionRadius(lickquidator)
+	offset := vision*3/4 + 1
+	hidden := 0
+	for _, dir := range [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		gx, gy := x+dir[0]*offset, y+dir[1]*offset
+		if !wm.IsPositionAvailable(gx, gy) {
+			continue
+		}
+		gotchi := generateGenericGotchi(wm, utils.NewUUID(wm.Rand), gx, gy, web3.DefaultSubgraphGotchiData, "farmer")
+		if zone.CanPerceive(lickquidator, gotchi) {
+			t.Fatalf("lickquidator can see a gotchi %d tiles away with vision %d", offset, vision)
+		}
+		hidden++
+	}
+	if hidden == 0 {
+		t.Fatal("nowhere to hide a gotchi")
+	}
+
+	zone.Pathfinder.BeginTick(wm.GetTick())
+	if target := actiontargeting.ResolveFallbackTarget(hunt); target != nil {
+		t.Fatalf("found a gotchi it can't see at %v", target.GetBounds())
+	}
+	if used := zone.Pathfinder.TickBudget - zone.Pathfinder.Remaining(); used != 0 {
+		t.Fatalf("searched %d nodes for %d gotchis it can't see", used, hidden)
+	}
+
+	gx, gy, found := wm.FindNearbyAvailablePosition(wm.Rand, x+2, y, 1, 0)
+	if !found {
+		t.Fatal("no room for a gotchi in plain sight")
+	}
+	seen := generateGenericGotchi(wm, utils.NewUUID(wm.Rand), gx, gy, web3.DefaultSubgraphGotchiData, "farmer")
+	if target := actiontargeting.ResolveFallbackTarget(hunt); target != seen {
+		t.Fatal("didn't find the gotchi in plain sight")
+	}
+}
@@ -0,0 +1,114 @@
+package world
+
+import (
+	"testing"
+	"thereaalm/action"
+	"thereaalm/entity"
+	"thereaalm/entity/resourceentity"
+	"thereaalm/pathfinding"
+	"thereaalm/stattypes"
+	"thereaalm/utils"
+	"thereaalm/web3"
+)
+
+// builderScene puts an empty handed builder by a damaged altar with a tree
+// and a boulder either side of it
+func builderScene(t *testing.T) (*WorldManager, *entity.Gotchi, *entity.Altar, *resourceentity.AlphaSlateBoulders) {
+	t.Helper()
+	wm := NewWorldManager(1, 7, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+
+	for y := zone.Y + 20; y < zone.Y+zone.Height-20; y++ {
+		for x := zone.X + 20; x < zone.X+zone.Width-20; x++ {
+			if !wm.IsAreaAvailable(pathfinding.Rect{Min: pathfinding.Point{X: x - 10, Y: y - 10}, W: 21, H: 21}) {
+				continue
+			}
+			altar := entity.NewAltar(utils.NewUUID(wm.Rand), x, y)
+			wm.AddEntity(altar)
+			altar.SetStat(stattypes.Pulse, 300)
+			wm.AddEntity(resourceentity.NewKekWoodTree(utils.NewUUID(wm.Rand), x+6, y))
+			boulder := resourceentity.NewAlphaSlateBoulders(utils.NewUUID(wm.Rand), x-6, y)
+			wm.AddEntity(boulder)
+
+			builder := generateGenericGotchi(wm, utils.NewUUID(wm.Rand), x, y+6, web3.DefaultSubgraphGotchiData, "builder")
+			builder.SelectionMode = action.SelectHighest
+			return wm, builder, altar, boulder
+		}
+	}
+	t.Fatal("no room for an altar")
+	return nil, nil, nil, nil
+}
+
+func plannedStepTypes(g *entity.Gotchi) []string {
+	var steps []string
+	for _, step := range g.ToReporting().PlannedSteps {
+		steps = append(steps, step.Type)
+	}
+	return steps
+}
+
+// TestBuilderFetchesMaterials has a builder with nothing to maintain the altar
+// with chop and mine first, then maintain it
+func TestBuilderFetchesMaterials(t *testing.T) {
+	wm, builder, altar, _ := builderScene(t)
+	if builder.GetItemQuantity("kekwood") != 0 || builder.GetItemQuantity("alphaslate") != 0 {
+		t.Fatal("builder isn't empty handed")
+	}
+
+	wm.StepN(1)
+	if builder.CurrentAction == nil || builder.CurrentAction.GetType() != "chop" {
+		t.Fatalf("builder started %v, want chop", builder.CurrentAction)
+	}
+	if goal, steps := builder.ToReporting().Goal, plannedStepTypes(builder); goal != "restore altar" ||
+		len(steps) != 2 || steps[0] != "mine" || steps[1] != "maintain" {
+		t.Fatalf("builder is working towards %q with %v to go, want restore altar after mine, maintain", goal, steps)
+	}
+
+	var ran []string
+	for i := 0; i < 1000 && altar.GetStat(stattypes.Pulse) <= 300; i++ {
+		wm.StepN(1)
+		if current := builder.CurrentAction; current != nil && (len(ran) == 0 || ran[len(ran)-1] != current.GetType()) {
+			ran = append(ran, current.GetType())
+		}
+	}
+	if altar.GetStat(stattypes.Pulse) <= 300 {
+		t.Fatalf("altar was never maintained, builder ran %v", ran)
+	}
+	if len(ran) < 3 || ran[0] != "chop" || ran[1] != "mine" || ran[2] != "maintain" {
+		t.Fatalf("builder ran %v, want chop, mine, maintain", ran)
+	}
+}
+
+// TestPlannedChainDroppedWhenStepInvalid takes away the boulder (and any other
+// the builder could go to) while it's chopping, so the rest of the chain can't
+// be done and mustn't be started
+func TestPlannedChainDroppedWhenStepInvalid(t *testing.T) {
+	wm, builder, _, _ := builderScene(t)
+
+	wm.StepN(1)
+	if builder.CurrentAction == nil || builder.CurrentAction.GetType() != "chop" {
+		t.Fatalf("builder started %v, want chop", builder.CurrentAction)
+	}
+
+	for _, zone := range wm.Zones {
+		for _, boulder := range zone.GetEntitiesByType("alphaslateboulders") {
+			wm.RemoveEntity(boulder)
+		}
+	}
+
+	for i := 0; i < 1000 && builder.GetItemQuantity("kekwood") == 0; i++ {
+		wm.StepN(1)
+	}
+	if builder.GetItemQuantity("kekwood") == 0 {
+		t.Fatal("builder never finished chopping")
+	}
+	wm.StepN(1)
+
+	report := builder.ToReporting()
+	if report.Goal != "" || len(report.PlannedSteps) != 0 {
+		t.Fatalf("builder still working towards %q with %v to go, doing %v", report.Goal, plannedStepTypes(builder), report.CurrentAction)
+	}
+	if builder.CurrentAction != nil && builder.CurrentAction.GetType() == "mine" {
+		t.Fatal("builder went mining with nothing to mine")
+	}
+}
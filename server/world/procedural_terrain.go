@@ -0,0 +1,224 @@
+package world
+
+import (
+	"thereaalm/utils"
+)
+
+// terrainParams tunes procedural terrain for a biome
+type terrainParams struct {
+	ObstacleDensity float64 // Rough fraction of tiles that are impassable
+	ResourceBand    float64 // Width of the noise band just below obstacles where resources grow
+}
+
+var defaultTerrainParams = terrainParams{ObstacleDensity: 0.2, ResourceBand: 0.08}
+
+// biomeTerrain holds per-biome overrides of defaultTerrainParams
+var biomeTerrain = map[string]terrainParams{
+	"daark_forest":        {ObstacleDensity: 0.35, ResourceBand: 0.12},
+	"tree_of_fud":         {ObstacleDensity: 0.3, ResourceBand: 0.12},
+	"defi_desert":         {ObstacleDensity: 0.08, ResourceBand: 0.04},
+	"open_steppe":         {ObstacleDensity: 0.06, ResourceBand: 0.06},
+	"yield_fields":        {ObstacleDensity: 0.1, ResourceBand: 0.12},
+	"rofl_reef":           {ObstacleDensity: 0.45, ResourceBand: 0.05},
+	"poly_lakes":          {ObstacleDensity: 0.4, ResourceBand: 0.08},
+	"aalpha_lake":         {ObstacleDensity: 0.4, ResourceBand: 0.08},
+	"laughing_peaks":      {ObstacleDensity: 0.4, ResourceBand: 0.06},
+	"mount_oomf":          {ObstacleDensity: 0.45, ResourceBand: 0.06},
+	"the_infinity_cliffs": {ObstacleDensity: 0.45, ResourceBand: 0.05},
+	"maagma_springs":      {ObstacleDensity: 0.3, ResourceBand: 0.05},
+	"caaverns":            {ObstacleDensity: 0.35, ResourceBand: 0.08},
+}
+
+const (
+	// spawn positions are only sampled every few tiles to keep spawn areas small
+	terrainSpawnStride = 4
+
+	// stream ids for terrain noise, kept clear of zone ids
+	terrainNoiseStream  = 1 << 32
+	resourceNoiseStream = 2 << 32
+)
+
+// open ground spawn types share one set of positions
+var openGroundSpawnTypes = []string{"gotchi", "lickvoid", "lickquidator", "altar", "shop"}
+
+var resourceSpawnTypes = []string{"fomoberrybush", "kekwoodtree", "alphaslateboulders"}
+
+// GenerateProceduralTerrain fills a zone with noise based obstacles and spawn
+// areas. It's used for zones whose biome has no tilemap and only depends on the
+// world seed and zone id, so the same seed always builds the same terrain.
+func GenerateProceduralTerrain(zone *Zone) []*SpawnArea {
+	params, ok := biomeTerrain[zone.Biome]
+	if !ok {
+		params = defaultTerrainParams
+	}
+
+	seed := zone.WorldManager.Seed
+	terrain := newValueNoise(utils.DeriveSeed(seed, terrainNoiseStream+int64(zone.ID)), zone.Width, zone.Height)
+	resources := newValueNoise(utils.DeriveSeed(seed, resourceNoiseStream+int64(zone.ID)), zone.Width, zone.Height)
+
+	// octave noise bunches up around 0.5 so work the thresholds out from the
+	// actual distribution, that way the densities mean what they say
+	samples := make([]float64, zone.Width*zone.Height)
+	for localY := 0; localY < zone.Height; localY++ {
+		for localX := 0; localX < zone.Width; localX++ {
+			samples[localY*zone.Width+localX] = terrain.Sample(localX, localY)
+		}
+	}
+	obstacleThreshold := noiseQuantile(samples, 1-params.ObstacleDensity)
+	resourceThreshold := noiseQuantile(samples, 1-params.ObstacleDensity-params.ResourceBand)
+
+	openGround := make([][2]int, 0)
+	resourceTiles := make([][2]int, 0)
+	resourceSamples := make([]float64, 0)
+
+	for localY := 0; localY < zone.Height; localY++ {
+		for localX := 0; localX < zone.Width; localX++ {
+			n := samples[localY*zone.Width+localX]
+			x, y := zone.X+localX, zone.Y+localY
+
+			if n >= obstacleThreshold {
+				zone.AddObstacle(x, y)
+				continue
+			}
+
+			if localX%terrainSpawnStride != 0 || localY%terrainSpawnStride != 0 {
+				continue
+			}
+
+			if n >= resourceThreshold {
+				resourceTiles = append(resourceTiles, [2]int{x, y})
+				resourceSamples = append(resourceSamples, resources.Sample(localX, localY))
+			} else {
+				openGround = append(openGround, [2]int{x, y})
+			}
+		}
+	}
+
+	// split the resource tiles evenly between resource types in patches
+	resourceAreas := make([]*SpawnArea, len(resourceSpawnTypes))
+	cutoffs := make([]float64, len(resourceSpawnTypes))
+	for i, spawnType := range resourceSpawnTypes {
+		resourceAreas[i] = NewSpawnArea(spawnType)
+		cutoffs[i] = noiseQuantile(resourceSamples, float64(i+1)/float64(len(resourceSpawnTypes)))
+	}
+	for i, pos := range resourceTiles {
+		idx := 0
+		for idx < len(cutoffs)-1 && resourceSamples[i] >= cutoffs[idx] {
+			idx++
+		}
+		resourceAreas[idx].AddPosition(pos[0], pos[1])
+	}
+
+	spawnAreas := make([]*SpawnArea, 0, len(openGroundSpawnTypes)+len(resourceAreas))
+	if len(openGround) > 0 {
+		for _, spawnType := range openGroundSpawnTypes {
+			spawnAreas = append(spawnAreas, &SpawnArea{
+				Positions:       openGround,
+				EntitySpawnType: spawnType,
+			})
+		}
+	}
+	for _, sa := range resourceAreas {
+		if len(sa.Positions) > 0 {
+			spawnAreas = append(spawnAreas, sa)
+		}
+	}
+
+	return spawnAreas
+}
+
+// valueNoise is a few octaves of smoothly interpolated lattice noise in [0, 1)
+type valueNoise struct {
+	octaves []noiseOctave
+}
+
+type noiseOctave struct {
+	cellSize int
+	weight   float64
+	lattice  [][]float64
+}
+
+func newValueNoise(seed int64, width, height int) *valueNoise {
+	cellSizes := []int{128, 64, 32, 16}
+
+	noise := &valueNoise{}
+	totalWeight := 0.0
+	weight := 1.0
+	for i, cellSize := range cellSizes {
+		// one extra lattice point on each axis so the far edge interpolates
+		cellsX := width/cellSize + 2
+		cellsY := height/cellSize + 2
+		octaveRand := utils.NewRand(utils.DeriveSeed(seed, int64(i)))
+
+		lattice := make([][]float64, cellsY)
+		for y := range lattice {
+			lattice[y] = make([]float64, cellsX)
+			for x := range lattice[y] {
+				lattice[y][x] = octaveRand.Float64()
+			}
+		}
+
+		noise.octaves = append(noise.octaves, noiseOctave{cellSize: cellSize, weight: weight, lattice: lattice})
+		totalWeight += weight
+		weight *= 0.5
+	}
+
+	for i := range noise.octaves {
+		noise.octaves[i].weight /= totalWeight
+	}
+	return noise
+}
+
+// Sample returns the noise value at a tile in local zone coordinates
+func (n *valueNoise) Sample(x, y int) float64 {
+	value := 0.0
+	for _, o := range n.octaves {
+		cx, cy := x/o.cellSize, y/o.cellSize
+		tx := smoothstep(float64(x%o.cellSize) / float64(o.cellSize))
+		ty := smoothstep(float64(y%o.cellSize) / float64(o.cellSize))
+
+		top := lerp(o.lattice[cy][cx], o.lattice[cy][cx+1], tx)
+		bottom := lerp(o.lattice[cy+1][cx], o.lattice[cy+1][cx+1], tx)
+		value += lerp(top, bottom, ty) * o.weight
+	}
+	return value
+}
+
+// noiseQuantile returns the value below which roughly q of the samples fall
+func noiseQuantile(samples []float64, q float64) float64 {
+	const bins = 1024
+
+	if q <= 0 {
+		return 0
+	}
+	if q >= 1 {
+		return 1
+	}
+
+	var histogram [bins]int
+	for _, v := range samples {
+		bin := int(v * bins)
+		if bin >= bins {
+			bin = bins - 1
+		}
+		histogram[bin]++
+	}
+
+	target := int(q * float64(len(samples)))
+	count := 0
+	for bin, n := range histogram {
+		count += n
+		if count >= target {
+			return float64(bin+1) / bins
+		}
+	}
+	return 1
+}
+
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,359 @@
+package world
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/types"
+)
+
+// minSpawnRegionSize is the smallest region we'll spawn things in, anything
+// smaller is a pocket walled off from the rest of the zone
+const minSpawnRegionSize = 64
+
+// RegionMap labels a zone's walkable tiles by which ones can reach each other
+// without crossing obstacles or buildings, so "can I get there at all?" is a
+// couple of lookups instead of a path search.
+//
+// Regions stop at the zone's edge, two tiles in different regions might still
+// be joined by a way round through a neighbouring zone.
+type RegionMap struct {
+	x, y          int
+	width, height int
+	isObstacle    func(x, y int) bool
+
+	cover     []uint8 // How many buildings cover each tile
+	labels    []int32 // Region of each tile, 0 = blocked. nil until first asked
+	sizes     map[int32]int
+	nextLabel int32
+	queue     []int // Reused by flood and rejoins
+
+	visited    []uint32 // Stamped by rejoins so it doesn't need clearing
+	visitStamp uint32
+}
+
+func NewRegionMap(x, y, width, height int, isObstacle func(x, y int) bool) *RegionMap {
+	return &RegionMap{
+		x:          x,
+		y:          y,
+		width:      width,
+		height:     height,
+		isObstacle: isObstacle,
+		cover:      make([]uint8, width*height),
+	}
+}
+
+// Region returns the region a tile is in, 0 if it's blocked or outside the zone
+func (r *RegionMap) Region(x, y int) int32 {
+	i, ok := r.index(x, y)
+	if !ok {
+		return 0
+	}
+	r.ensureBuilt()
+	return r.labels[i]
+}
+
+// Size returns how many tiles a region has
+func (r *RegionMap) Size(region int32) int {
+	r.ensureBuilt()
+	return r.sizes[region]
+}
+
+// Connected is true if both tiles are walkable and in the same region
+func (r *RegionMap) Connected(x0, y0, x1, y1 int) bool {
+	region := r.Region(x0, y0)
+	return region != 0 && region == r.Region(x1, y1)
+}
+
+// AddCover marks a building as covering area (or uncovering it for a negative
+// delta) and relabels whatever that changed
+func (r *RegionMap) AddCover(area pathfinding.Rect, delta int) {
+	r.forEachIndexIn(area, func(i int) {
+		r.cover[i] = uint8(int(r.cover[i]) + delta)
+	})
+	r.Update(area)
+}
+
+// Update relabels after tiles in area became blocked or walkable. Blocking a
+// tile floods whatever region it might have split, opening one merges the
+// smaller regions it joins into the biggest.
+func (r *RegionMap) Update(area pathfinding.Rect) {
+	if r.labels == nil {
+		// not built yet, it'll see the change when it is
+		return
+	}
+
+	var split []int32
+	var opened []int
+	r.forEachIndexIn(area, func(i int) {
+		blocked := r.isBlocked(i)
+		switch {
+		case blocked && r.labels[i] != 0:
+			if len(split) == 0 || split[len(split)-1] != r.labels[i] {
+				split = append(split, r.labels[i])
+			}
+			r.resize(r.labels[i], -1)
+			r.labels[i] = 0
+		case !blocked && r.labels[i] == 0:
+			opened = append(opened, i)
+		}
+	})
+
+	for _, region := range split {
+		r.resplit(area, region)
+	}
+
+	for _, i := range opened {
+		if r.labels[i] != 0 {
+			continue // joined up by an earlier tile
+		}
+
+		// the biggest neighbouring region swallows the rest
+		best := int32(0)
+		r.forEachNeighbour(i, func(j int) {
+			if l := r.labels[j]; l != 0 && (best == 0 || r.sizes[l] > r.sizes[best]) {
+				best = l
+			}
+		})
+		if best == 0 {
+			best = r.newLabel()
+		}
+		r.flood(i, best, func(j int) bool {
+			return r.labels[j] != best && !r.isBlocked(j)
+		})
+	}
+}
+
+// rejoinSearchLimit is how many tiles resplit looks through for a way round a
+// new blocker before giving up and relabelling the whole region
+const rejoinSearchLimit = 2048
+
+// resplit gives every separate piece of region left around area its own label
+func (r *RegionMap) resplit(area pathfinding.Rect, region int32) {
+	if area.W == 1 && area.H == 1 && r.ringConnected(area.Min.X, area.Min.Y) {
+		return
+	}
+
+	grown := pathfinding.Rect{
+		Min: pathfinding.Point{X: area.Min.X - 1, Y: area.Min.Y - 1},
+		W:   area.W + 2,
+		H:   area.H + 2,
+	}
+	var seeds []int
+	r.forEachIndexIn(grown, func(i int) {
+		if r.labels[i] == region {
+			seeds = append(seeds, i)
+		}
+	})
+	if r.rejoins(seeds, region) {
+		return
+	}
+
+	for _, i := range seeds {
+		if r.labels[i] == region {
+			r.flood(i, r.newLabel(), func(j int) bool { return r.labels[j] == region })
+		}
+	}
+}
+
+// rejoins is true if a short search from the first seed finds all the others,
+// which is the usual case when something's just been built in the open
+func (r *RegionMap) rejoins(seeds []int, region int32) bool {
+	if len(seeds) <= 1 {
+		return true
+	}
+
+	if r.visited == nil {
+		r.visited = make([]uint32, len(r.labels))
+	}
+	r.visitStamp++
+
+	wanted := make(map[int]bool, len(seeds))
+	for _, i := range seeds[1:] {
+		wanted[i] = true
+	}
+
+	r.queue = append(r.queue[:0], seeds[0])
+	r.visited[seeds[0]] = r.visitStamp
+	for head := 0; head < len(r.queue) && head < rejoinSearchLimit; head++ {
+		r.forEachNeighbour(r.queue[head], func(j int) {
+			if r.visited[j] == r.visitStamp || r.labels[j] != region {
+				return
+			}
+			r.visited[j] = r.visitStamp
+			delete(wanted, j)
+			r.queue = append(r.queue, j)
+		})
+		if len(wanted) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ringOffsets walk clockwise round a tile, edge neighbours are the even ones
+var ringOffsets = [8]pathfinding.Point{
+	{X: 0, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 0}, {X: 1, Y: 1},
+	{X: 0, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: 0}, {X: -1, Y: -1},
+}
+
+// ringConnected is true if the walkable tiles next to (x, y) can still reach
+// each other through the 8 tiles around it, blocking it can't split anything
+func (r *RegionMap) ringConnected(x, y int) bool {
+	var walkable [8]bool
+	for k, offset := range ringOffsets {
+		i, ok := r.index(x+offset.X, y+offset.Y)
+		walkable[k] = ok && r.labels[i] != 0
+	}
+
+	allWalkable := true
+	for _, w := range walkable {
+		allWalkable = allWalkable && w
+	}
+	if allWalkable {
+		return true
+	}
+
+	// count runs of walkable ring tiles that include an edge neighbour
+	runs := 0
+	for k := 0; k < 8; k++ {
+		if !walkable[k] || walkable[(k+7)%8] {
+			continue // not the start of a run
+		}
+		for j := k; walkable[j%8]; j++ {
+			if j%2 == 0 {
+				runs++
+				break
+			}
+		}
+	}
+	return runs <= 1
+}
+
+func (r *RegionMap) ensureBuilt() {
+	if r.labels != nil {
+		return
+	}
+
+	r.labels = make([]int32, r.width*r.height)
+	r.sizes = make(map[int32]int)
+	for i := range r.labels {
+		if r.labels[i] == 0 && !r.isBlocked(i) {
+			r.flood(i, r.newLabel(), func(j int) bool {
+				return r.labels[j] == 0 && !r.isBlocked(j)
+			})
+		}
+	}
+}
+
+// flood relabels start and every tile reachable from it that match accepts
+func (r *RegionMap) flood(start int, label int32, match func(i int) bool) {
+	r.queue = append(r.queue[:0], start)
+	r.resize(r.labels[start], -1)
+	r.labels[start] = label
+	r.resize(label, 1)
+
+	for len(r.queue) > 0 {
+		i := r.queue[len(r.queue)-1]
+		r.queue = r.queue[:len(r.queue)-1]
+
+		r.forEachNeighbour(i, func(j int) {
+			if !match(j) {
+				return
+			}
+			r.resize(r.labels[j], -1)
+			r.labels[j] = label
+			r.resize(label, 1)
+			r.queue = append(r.queue, j)
+		})
+	}
+}
+
+func (r *RegionMap) resize(region int32, delta int) {
+	if region == 0 {
+		return
+	}
+	if r.sizes[region] += delta; r.sizes[region] <= 0 {
+		delete(r.sizes, region)
+	}
+}
+
+func (r *RegionMap) newLabel() int32 {
+	r.nextLabel++
+	return r.nextLabel
+}
+
+func (r *RegionMap) isBlocked(i int) bool {
+	return r.cover[i] > 0 || r.isObstacle(r.x+i%r.width, r.y+i/r.width)
+}
+
+func (r *RegionMap) index(x, y int) (int, bool) {
+	lx, ly := x-r.x, y-r.y
+	if lx < 0 || ly < 0 || lx >= r.width || ly >= r.height {
+		return 0, false
+	}
+	return ly*r.width + lx, true
+}
+
+func (r *RegionMap) forEachNeighbour(i int, fn func(j int)) {
+	lx, ly := i%r.width, i/r.width
+	if ly > 0 {
+		fn(i - r.width)
+	}
+	if lx < r.width-1 {
+		fn(i + 1)
+	}
+	if ly < r.height-1 {
+		fn(i + r.width)
+	}
+	if lx > 0 {
+		fn(i - 1)
+	}
+}
+
+// forEachIndexIn visits the tiles of area inside the zone, in row order
+func (r *RegionMap) forEachIndexIn(area pathfinding.Rect, fn func(i int)) {
+	for y := max(area.Min.Y, r.y); y < min(area.Min.Y+area.H, r.y+r.height); y++ {
+		for x := max(area.Min.X, r.x); x < min(area.Min.X+area.W, r.x+r.width); x++ {
+			fn((y-r.y)*r.width + (x - r.x))
+		}
+	}
+}
+
+// blocksRegions is true for buildings, anything bigger than a tile
+func blocksRegions(e interfaces.IEntity) bool {
+	return e.GetFootprint() != types.SingleTile
+}
+
+// AreConnected is true if you can walk between two tiles without crossing
+// obstacles or buildings. Tiles outside the zone get the benefit of the doubt.
+func (z *Zone) AreConnected(x0, y0, x1, y1 int) bool {
+	if !z.isPositionWithinZone(x0, y0) || !z.isPositionWithinZone(x1, y1) {
+		return true
+	}
+	return z.Regions.Connected(x0, y0, x1, y1)
+}
+
+// IsReachable is true if the target has a tile next to it in the same region
+// as (fromX, fromY), so a path there might exist. It's a quick check to throw
+// out hopeless targets before searching, not a promise a path is free.
+func (z *Zone) IsReachable(fromX, fromY int, target interfaces.IEntity) bool {
+	from := z.Regions.Region(fromX, fromY)
+	if from == 0 {
+		return true // off the zone, or somewhere we can't judge
+	}
+
+	for _, tile := range target.GetBounds().Perimeter() {
+		if !z.isPositionWithinZone(tile.X, tile.Y) || z.Regions.Region(tile.X, tile.Y) == from {
+			return true
+		}
+	}
+	return false
+}
+
+// isInOpenRegion is false for tiles walled into a small pocket (or blocked)
+func (z *Zone) isInOpenRegion(x, y int) bool {
+	if !z.isPositionWithinZone(x, y) {
+		return true
+	}
+	return z.Regions.Size(z.Regions.Region(x, y)) >= minSpawnRegionSize
+}
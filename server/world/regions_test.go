@@ -0,0 +1,151 @@
+package world
+
+import (
+	"math/rand"
+	"testing"
+	"thereaalm/pathfinding"
+)
+
+// obstacleGrid is a map of obstacles for a region map at the origin
+type obstacleGrid struct {
+	width   int
+	blocked []bool
+}
+
+func newObstacleGrid(width, height int) *obstacleGrid {
+	return &obstacleGrid{width: width, blocked: make([]bool, width*height)}
+}
+
+func (g *obstacleGrid) isObstacle(x, y int) bool { return g.blocked[y*g.width+x] }
+
+// set blocks or clears a tile and tells r about it
+func (g *obstacleGrid) set(r *RegionMap, x, y int, blocked bool) {
+	g.blocked[y*g.width+x] = blocked
+	r.Update(pathfinding.PointRect(pathfinding.Point{X: x, Y: y}))
+}
+
+// requireFreshLabels checks r's labels split the tiles up just like a map
+// built from scratch would, and that its sizes add up
+func requireFreshLabels(t *testing.T, r *RegionMap) {
+	t.Helper()
+	fresh := NewRegionMap(r.x, r.y, r.width, r.height, r.isObstacle)
+	copy(fresh.cover, r.cover)
+	fresh.ensureBuilt()
+
+	toFresh := map[int32]int32{}
+	fromFresh := map[int32]int32{}
+	counts := map[int32]int{}
+	for i, label := range r.labels {
+		freshLabel := fresh.labels[i]
+		if (label == 0) != (freshLabel == 0) {
+			t.Fatalf("tile %d,%d is in region %d, fresh it's in %d", i%r.width, i/r.width, label, freshLabel)
+		}
+		if label == 0 {
+			continue
+		}
+		if want, ok := toFresh[label]; ok && want != freshLabel {
+			t.Fatalf("region %d is split up fresh (%d and %d)", label, want, freshLabel)
+		}
+		if want, ok := fromFresh[freshLabel]; ok && want != label {
+			t.Fatalf("fresh region %d is split into %d and %d", freshLabel, want, label)
+		}
+		toFresh[label], fromFresh[freshLabel] = freshLabel, label
+		counts[label]++
+	}
+
+	if len(r.sizes) != len(counts) {
+		t.Fatalf("%d regions sized, %d have tiles", len(r.sizes), len(counts))
+	}
+	for label, count := range counts {
+		if r.sizes[label] != count {
+			t.Fatalf("region %d is sized %d, has %d tiles", label, r.sizes[label], count)
+		}
+	}
+}
+
+// TestRegionsMatchFreshAfterEdits makes random obstacle and building edits
+// and checks the incremental labels against a fresh build after each one
+func TestRegionsMatchFreshAfterEdits(t *testing.T) {
+	const size = 48
+	rng := rand.New(rand.NewSource(1))
+	grid := newObstacleGrid(size, size)
+	r := NewRegionMap(0, 0, size, size, grid.isObstacle)
+	r.ensureBuilt()
+
+	var buildings []pathfinding.Rect
+	for edit := 0; edit < 2000; edit++ {
+		switch op := rng.Intn(10); {
+		case op < 5:
+			grid.set(r, rng.Intn(size), rng.Intn(size), true)
+		case op < 7:
+			grid.set(r, rng.Intn(size), rng.Intn(size), false)
+		case op < 8:
+			// walls make the splits
+			x, y, horizontal := rng.Intn(size), rng.Intn(size), rng.Intn(2) == 0
+			for k := 0; k < 12; k++ {
+				if horizontal && x+k < size {
+					grid.set(r, x+k, y, true)
+				} else if !horizontal && y+k < size {
+					grid.set(r, x, y+k, true)
+				}
+			}
+		case op < 9 || len(buildings) == 0:
+			// buildings can hang off the edge of the zone
+			w, h := 1+rng.Intn(5), 1+rng.Intn(5)
+			area := pathfinding.Rect{Min: pathfinding.Point{X: rng.Intn(size+4) - 2, Y: rng.Intn(size+4) - 2}, W: w, H: h}
+			r.AddCover(area, 1)
+			buildings = append(buildings, area)
+		default:
+			k := rng.Intn(len(buildings))
+			r.AddCover(buildings[k], -1)
+			buildings = append(buildings[:k], buildings[k+1:]...)
+		}
+		requireFreshLabels(t, r)
+	}
+}
+
+// TestRegionsSplitPastRejoinLimit blocks off halves of a map too big for
+// rejoins to search, with and without a long way round
+func TestRegionsSplitPastRejoinLimit(t *testing.T) {
+	const size = 96
+	if size*size/2 <= rejoinSearchLimit {
+		t.Fatal("map is small enough to search, make it bigger")
+	}
+
+	tests := []struct {
+		name        string
+		gap         bool // leave a way round at the far end of the wall
+		wantRegions int
+	}{
+		{"split in two", false, 2},
+		{"long way round", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid := newObstacleGrid(size, size)
+			r := NewRegionMap(0, 0, size, size, grid.isObstacle)
+			r.ensureBuilt()
+
+			// wall down the middle, leaving the top open
+			last := size - 1
+			if tt.gap {
+				last = size - 2
+			}
+			for y := 1; y <= last; y++ {
+				grid.set(r, size/2, y, true)
+				requireFreshLabels(t, r)
+			}
+
+			// then a building across the top of it
+			r.AddCover(pathfinding.Rect{Min: pathfinding.Point{X: size/2 - 2, Y: 0}, W: 5, H: 2}, 1)
+			requireFreshLabels(t, r)
+			if len(r.sizes) != tt.wantRegions {
+				t.Fatalf("got %d regions, want %d", len(r.sizes), tt.wantRegions)
+			}
+			if connected := r.Connected(0, 0, size-1, 0); connected != (tt.wantRegions == 1) {
+				t.Fatalf("corners connected = %v with %d regions", connected, tt.wantRegions)
+			}
+		})
+	}
+}
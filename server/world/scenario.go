@@ -0,0 +1,405 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"thereaalm/action"
+	"thereaalm/stattypes"
+)
+
+// Scenario describes the starting population of a world so new test worlds
+// can be made without touching Go
+type Scenario struct {
+	Name  string         `json:"name"`
+	Zones []ZoneScenario `json:"zones"`
+
+	source string // File the scenario came from, for error messages
+}
+
+// ZoneScenario lists what to spawn in a single zone
+type ZoneScenario struct {
+	ZoneID      int          `json:"zoneId"`
+	Populations []Population `json:"populations"`
+}
+
+// Population is a number of one entity type to spawn in a zone
+type Population struct {
+	Type      string             `json:"type"`
+	Count     int                `json:"count"`
+	SpawnArea string             `json:"spawnArea,omitempty"` // Spawn area type, defaults to Type
+	Jobs      map[string]float64 `json:"jobs,omitempty"`      // Gotchis only, job name -> weight
+	GotchiIDs []string           `json:"gotchiIds,omitempty"` // Gotchis only, picked from at random
+	Stats     map[string]float64 `json:"stats,omitempty"`     // Initial stat overrides
+	Spawner   *PopulationSpawner `json:"spawner,omitempty"`   // Keeps the population topped up after startup
+
+	// How members pick their next action, gotchis and lickquidators only
+	SelectionMode action.SelectionMode `json:"selectionMode,omitempty"` // softmax (default) or highest
+	Temperature   float64              `json:"temperature,omitempty"`   // Softmax temperature, 0 uses the default
+}
+
+// PopulationSpawner configures the zone spawn rule for a population
+type PopulationSpawner struct {
+	TargetPopulation int     `json:"targetPopulation,omitempty"` // Defaults to the population's count
+	SpawnRate        float64 `json:"spawnRate"`                  // Spawns per second of game time
+	MaxDensity       int     `json:"maxDensity,omitempty"`
+	DensityRadius    int     `json:"densityRadius,omitempty"`
+	MinSpacing       int     `json:"minSpacing,omitempty"`
+	RespawnDelay_s   float64 `json:"respawnDelay_s,omitempty"`
+}
+
+// newSpawnRule builds the spawn rule for a population with a spawner
+func (p *Population) newSpawnRule() *SpawnRule {
+	target := p.Spawner.TargetPopulation
+	if target == 0 {
+		target = p.Count
+	}
+
+	return &SpawnRule{
+		EntityType:       p.Type,
+		SpawnArea:        p.GetSpawnArea(),
+		TargetPopulation: target,
+		SpawnRate:        p.Spawner.SpawnRate,
+		MaxDensity:       p.Spawner.MaxDensity,
+		DensityRadius:    p.Spawner.DensityRadius,
+		MinSpacing:       p.Spawner.MinSpacing,
+		RespawnDelay_s:   p.Spawner.RespawnDelay_s,
+		Jobs:             p.Jobs,
+		Stats:            p.Stats,
+		SelectionMode:    p.SelectionMode,
+		Temperature:      p.Temperature,
+	}
+}
+
+// GetSpawnArea returns the spawn area type this population spawns in
+func (p *Population) GetSpawnArea() string {
+	if p.SpawnArea == "" {
+		return p.Type
+	}
+	return p.SpawnArea
+}
+
+// sortedJobs returns a population's jobs in a fixed order so picking one is deterministic
+func sortedJobs(weights map[string]float64) []string {
+	jobs := make([]string, 0, len(weights))
+	for job := range weights {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	return jobs
+}
+
+var scenarioEntityTypes = map[string]bool{
+	"gotchi":             true,
+	"lickvoid":           true,
+	"lickquidator":       true,
+	"fomoberrybush":      true,
+	"kekwoodtree":        true,
+	"alphaslateboulders": true,
+	"altar":              true,
+	"shop":               true,
+}
+
+// entity types that have stats that can be overridden
+var scenarioStatTypes = map[string]bool{
+	"gotchi":       true,
+	"lickvoid":     true,
+	"lickquidator": true,
+	"altar":        true,
+	"shop":         true,
+}
+
+// entity types that pick their own actions
+var scenarioPlannerTypes = map[string]bool{
+	"gotchi":       true,
+	"lickquidator": true,
+}
+
+var scenarioSelectionModes = map[action.SelectionMode]bool{
+	action.SelectSoftmax: true,
+	action.SelectHighest: true,
+}
+
+var scenarioJobs = map[string]bool{
+	"mercenary": true,
+	"farmer":    true,
+	"minerjack": true,
+	"builder":   true,
+	"explorer":  true,
+}
+
+var scenarioStats = map[string]bool{
+	stattypes.Ecto:       true,
+	stattypes.Spark:      true,
+	stattypes.Pulse:      true,
+	stattypes.MaxPulse:   true,
+	stattypes.StakedGHST: true,
+	stattypes.TreatTotal: true,
+	stattypes.NRG:        true,
+	stattypes.AGG:        true,
+	stattypes.SPK:        true,
+	stattypes.BRN:        true,
+	stattypes.EYS:        true,
+	stattypes.EYC:        true,
+}
+
+// ScenarioError points at the part of a scenario file that's wrong
+type ScenarioError struct {
+	File string
+	Path string // e.g. zones[0].populations[2].count
+	Msg  string
+}
+
+func (e *ScenarioError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Path, e.Msg)
+}
+
+// ScenarioErrors is every problem found while validating a scenario
+type ScenarioErrors []*ScenarioError
+
+func (errs ScenarioErrors) Error() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d scenario error(s):", len(errs))
+	for _, err := range errs {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// LoadScenario reads and validates a scenario file
+func LoadScenario(filePath string) (*Scenario, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseScenario(filePath, data)
+}
+
+// ParseScenario decodes and validates scenario JSON. Errors say where in the
+// file the problem is; name is only used in error messages.
+func ParseScenario(name string, data []byte) (*Scenario, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var scenario Scenario
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, decodeScenarioError(name, data, decoder.InputOffset(), err)
+	}
+
+	scenario.source = name
+	if errs := scenario.Validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return &scenario, nil
+}
+
+// decodeScenarioError adds the line and column to JSON decoding errors
+func decodeScenarioError(name string, data []byte, offset int64, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := lineAndColumn(data, syntaxErr.Offset)
+		return &ScenarioError{File: name, Msg: fmt.Sprintf("line %d, column %d: %v", line, col, err)}
+	case errors.As(err, &typeErr):
+		line, col := lineAndColumn(data, typeErr.Offset)
+		return &ScenarioError{File: name, Path: scenarioFieldPath(typeErr.Field),
+			Msg: fmt.Sprintf("line %d, column %d: expected %s but got %s", line, col, typeErr.Type, typeErr.Value)}
+	default:
+		// the decoder has stopped just past the problem, except for unknown
+		// fields which it only finds once it's read the whole value
+		if at := unknownFieldOffset(data, err); at >= 0 {
+			offset = int64(at)
+		}
+		line, col := lineAndColumn(data, offset)
+		return &ScenarioError{File: name, Msg: fmt.Sprintf("line %d, column %d: %v", line, col, err)}
+	}
+}
+
+// unknownFieldOffset finds the first key named in an unknown field error, -1
+// if err isn't one or the key can't be found
+func unknownFieldOffset(data []byte, err error) int {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return -1
+	}
+	key := regexp.MustCompile(regexp.QuoteMeta(quoted) + `\s*:`)
+	if loc := key.FindIndex(data); loc != nil {
+		return loc[0]
+	}
+	return -1
+}
+
+// scenarioFieldPath turns the decoder's "zones.0.zoneId" into "zones[0].zoneId"
+func scenarioFieldPath(field string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			fmt.Fprintf(&b, "[%s]", part)
+			continue
+		}
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, col := 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func (s *Scenario) validateSpawner(path string, spawner *PopulationSpawner, fail func(path, format string, args ...interface{})) {
+	if spawner.TargetPopulation < 0 {
+		fail(path+".targetPopulation", "must not be negative, got %d", spawner.TargetPopulation)
+	}
+	if spawner.SpawnRate <= 0 {
+		fail(path+".spawnRate", "must be positive, got %g", spawner.SpawnRate)
+	}
+	if spawner.MaxDensity < 0 {
+		fail(path+".maxDensity", "must not be negative, got %d", spawner.MaxDensity)
+	}
+	if spawner.DensityRadius < 0 {
+		fail(path+".densityRadius", "must not be negative, got %d", spawner.DensityRadius)
+	}
+	if spawner.MaxDensity > 0 && spawner.DensityRadius == 0 {
+		fail(path+".densityRadius", "must be set when maxDensity is")
+	}
+	if spawner.MinSpacing < 0 {
+		fail(path+".minSpacing", "must not be negative, got %d", spawner.MinSpacing)
+	}
+	if spawner.RespawnDelay_s < 0 {
+		fail(path+".respawnDelay_s", "must not be negative, got %g", spawner.RespawnDelay_s)
+	}
+}
+
+// Validate checks the scenario for anything the loader can catch without a
+// world. Zone ids are checked against the world when it's applied.
+func (s *Scenario) Validate() ScenarioErrors {
+	var errs ScenarioErrors
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, &ScenarioError{File: s.source, Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Zones) == 0 {
+		fail("zones", "must list at least one zone")
+	}
+
+	seenZones := make(map[int]int)
+	for i, zone := range s.Zones {
+		zonePath := fmt.Sprintf("zones[%d]", i)
+
+		if zone.ZoneID < 0 {
+			fail(zonePath+".zoneId", "must not be negative, got %d", zone.ZoneID)
+		}
+		if first, ok := seenZones[zone.ZoneID]; ok {
+			fail(zonePath+".zoneId", "zone %d is already listed at zones[%d]", zone.ZoneID, first)
+		} else {
+			seenZones[zone.ZoneID] = i
+		}
+
+		spawnedTypes := make(map[string]int)
+		for j, pop := range zone.Populations {
+			popPath := fmt.Sprintf("%s.populations[%d]", zonePath, j)
+
+			if !scenarioEntityTypes[pop.Type] {
+				fail(popPath+".type", "unknown entity type %q", pop.Type)
+			}
+			if pop.Count < 0 {
+				fail(popPath+".count", "must not be negative, got %d", pop.Count)
+			}
+
+			if pop.Type != "gotchi" {
+				if len(pop.Jobs) > 0 {
+					fail(popPath+".jobs", "only gotchis have jobs")
+				}
+				if len(pop.GotchiIDs) > 0 {
+					fail(popPath+".gotchiIds", "only gotchis have gotchi ids")
+				}
+			}
+
+			totalWeight := 0.0
+			for _, job := range sortedJobs(pop.Jobs) {
+				weight := pop.Jobs[job]
+				if !scenarioJobs[job] {
+					fail(fmt.Sprintf("%s.jobs.%s", popPath, job), "unknown job")
+				}
+				if weight < 0 {
+					fail(fmt.Sprintf("%s.jobs.%s", popPath, job), "weight must not be negative, got %g", weight)
+				}
+				totalWeight += weight
+			}
+			if len(pop.Jobs) > 0 && totalWeight <= 0 {
+				fail(popPath+".jobs", "weights must add up to more than zero")
+			}
+
+			for k, id := range pop.GotchiIDs {
+				if id == "" {
+					fail(fmt.Sprintf("%s.gotchiIds[%d]", popPath, k), "must not be empty")
+				}
+			}
+
+			if len(pop.Stats) > 0 && scenarioEntityTypes[pop.Type] && !scenarioStatTypes[pop.Type] {
+				fail(popPath+".stats", "%s has no stats", pop.Type)
+			}
+			stats := make([]string, 0, len(pop.Stats))
+			for stat := range pop.Stats {
+				stats = append(stats, stat)
+			}
+			sort.Strings(stats)
+			for _, stat := range stats {
+				if !scenarioStats[stat] {
+					fail(fmt.Sprintf("%s.stats.%s", popPath, stat), "unknown stat")
+				}
+			}
+
+			if (pop.SelectionMode != "" || pop.Temperature != 0) && scenarioEntityTypes[pop.Type] && !scenarioPlannerTypes[pop.Type] {
+				fail(popPath, "%s doesn't pick actions, selectionMode and temperature don't apply", pop.Type)
+			}
+			if pop.SelectionMode != "" && !scenarioSelectionModes[pop.SelectionMode] {
+				fail(popPath+".selectionMode", "unknown selection mode %q, want softmax or highest", pop.SelectionMode)
+			}
+			if pop.Temperature < 0 {
+				fail(popPath+".temperature", "must not be negative, got %g", pop.Temperature)
+			}
+
+			if pop.Spawner != nil {
+				s.validateSpawner(popPath+".spawner", pop.Spawner, fail)
+
+				// two rules for one type would both count the same entities
+				if first, ok := spawnedTypes[pop.Type]; ok {
+					fail(popPath+".spawner", "%s already has a spawner at %s.populations[%d]", pop.Type, zonePath, first)
+				} else {
+					spawnedTypes[pop.Type] = j
+				}
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,262 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"thereaalm/action"
+	"thereaalm/entity"
+	"thereaalm/entity/resourceentity"
+	"thereaalm/interfaces"
+	"thereaalm/utils"
+	"thereaalm/web3"
+)
+
+// every gotchi job, in a fixed order for picking at random
+var gotchiJobs = []string{"builder", "explorer", "farmer", "mercenary", "minerjack"}
+
+// how many random tiles to try when a zone has no usable spawn area
+const randomSpawnAttempts = 10
+
+// ApplyScenario spawns every population in a scenario. Zone ids are checked
+// up front so a scenario that doesn't fit the world spawns nothing.
+func (wm *WorldManager) ApplyScenario(scenario *Scenario) error {
+	var errs ScenarioErrors
+	for i, zs := range scenario.Zones {
+		if wm.GetZoneByID(zs.ZoneID) == nil {
+			errs = append(errs, &ScenarioError{
+				File: scenario.source,
+				Path: fmt.Sprintf("zones[%d].zoneId", i),
+				Msg:  fmt.Sprintf("zone %d doesn't exist, the world has %d zones", zs.ZoneID, len(wm.Zones)),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	gotchiData := fetchScenarioGotchis(scenario)
+
+	for _, zs := range scenario.Zones {
+		zone := wm.Zones[zs.ZoneID].(*Zone)
+		for i := range zs.Populations {
+			pop := &zs.Populations[i]
+			spawned := wm.spawnPopulation(zone, pop, gotchiData)
+			log.Printf("Spawned %d/%d %s in zone %d", len(spawned), pop.Count, pop.Type, zone.ID)
+
+			if pop.Spawner != nil {
+				zone.Spawner.AddRule(pop.newSpawnRule(), spawned)
+			}
+		}
+	}
+
+	log.Printf("Applied scenario '%s'", scenario.Name)
+	return nil
+}
+
+// fetchScenarioGotchis grabs subgraph data for every gotchi id in the
+// scenario. Gotchis we can't fetch fall back to the default gotchi data.
+func fetchScenarioGotchis(scenario *Scenario) map[string]web3.SubgraphGotchiData {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for _, zs := range scenario.Zones {
+		for _, pop := range zs.Populations {
+			for _, id := range pop.GotchiIDs {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return map[string]web3.SubgraphGotchiData{}
+	}
+
+	sort.Strings(ids)
+	gotchisMap, err := web3.FetchGotchisByIDs(ids)
+	if err != nil {
+		log.Printf("WARNING: Failed to fetch scenario gotchis, using default gotchi data: %v", err)
+		return map[string]web3.SubgraphGotchiData{}
+	}
+	return gotchisMap
+}
+
+// spawnPopulation spawns one population into a zone and returns what made it
+func (wm *WorldManager) spawnPopulation(zone *Zone, pop *Population, gotchiData map[string]web3.SubgraphGotchiData) []interfaces.IEntity {
+	spawnArea := zone.GetSpawnArea(pop.GetSpawnArea())
+	if spawnArea == nil {
+		log.Printf("No %s spawn area in zone %d, falling back to random positions", pop.GetSpawnArea(), zone.ID)
+	}
+
+	jobs := sortedJobs(pop.Jobs)
+
+	spawned := make([]interfaces.IEntity, 0, pop.Count)
+	for i := 0; i < pop.Count; i++ {
+		x, y, found := wm.findSpawnPosition(zone, spawnArea)
+		if !found {
+			continue
+		}
+
+		e, err := wm.spawnEntity(wm.Rand, pop.Type, x, y, wm.pickScenarioGotchi(pop, gotchiData), pickScenarioJob(wm.Rand, pop.Jobs, jobs))
+		if errors.Is(err, errFootprintBlocked) {
+			continue
+		}
+		if err != nil {
+			// Validate() rejects these so we should never get here
+			log.Printf("WARNING: Can't spawn entity type %s: %v", pop.Type, err)
+			return spawned
+		}
+
+		applyScenarioStats(e, pop.Stats)
+		applyScenarioSelection(e, pop.SelectionMode, pop.Temperature)
+		spawned = append(spawned, e)
+	}
+
+	return spawned
+}
+
+var (
+	errUnknownEntityType = errors.New("unknown entity type")
+	errFootprintBlocked  = errors.New("footprint overlaps something")
+)
+
+// spawnEntity creates an entity of the given type and adds it to the world.
+// Gotchi data and job are ignored for everything but gotchis. Entities bigger
+// than a tile are only added if their whole footprint is free and in one zone,
+// as only the zone holding their anchor knows about them.
+func (wm *WorldManager) spawnEntity(rng *rand.Rand, entityType string, x, y int, gotchiData web3.SubgraphGotchiData, job string) (interfaces.IEntity, error) {
+	id := utils.NewUUID(rng)
+	var e interfaces.IEntity
+	switch entityType {
+	case "gotchi":
+		return generateGenericGotchi(wm, id, x, y, gotchiData, job), nil
+	case "lickquidator":
+		return generateGenericLickquidator(wm, id, x, y), nil
+	case "lickvoid":
+		e = entity.NewLickVoid(id, x, y)
+	case "fomoberrybush":
+		e = resourceentity.NewFomoBerryBush(id, x, y)
+	case "kekwoodtree":
+		e = resourceentity.NewKekWoodTree(id, x, y)
+	case "alphaslateboulders":
+		e = resourceentity.NewAlphaSlateBoulders(id, x, y)
+	case "altar":
+		e = entity.NewAltar(id, x, y)
+	case "shop":
+		e = entity.NewShop(id, x, y)
+	default:
+		return nil, errUnknownEntityType
+	}
+
+	if !wm.IsAreaAvailable(e.GetBounds()) {
+		return nil, errFootprintBlocked
+	}
+	if !wm.isInOneZone(e.GetBounds()) {
+		return nil, fmt.Errorf("%w: crosses a zone edge", errFootprintBlocked)
+	}
+
+	wm.AddEntity(e)
+	return e, nil
+}
+
+// findSpawnPosition picks a free tile from the spawn area, or anywhere in the
+// zone if there isn't one, nudging to a nearby free tile if it's taken. Tiles
+// walled into small pockets are skipped.
+func (wm *WorldManager) findSpawnPosition(zone *Zone, spawnArea *SpawnArea) (int, int, bool) {
+	var x, y int
+	found := false
+
+	if spawnArea != nil {
+		x, y, found = spawnArea.GetRandomPosition(wm.Rand)
+	}
+
+	if !found {
+		for attempts := 0; attempts < randomSpawnAttempts; attempts++ {
+			x = zone.X + wm.Rand.Intn(zone.Width)
+			y = zone.Y + wm.Rand.Intn(zone.Height)
+			if zone.IsPositionAvailable(x, y) && zone.isInOpenRegion(x, y) {
+				return x, y, true
+			}
+		}
+		return 0, 0, false
+	}
+
+	if !zone.IsPositionAvailable(x, y) {
+		if x, y, found = wm.FindNearbyAvailablePosition(wm.Rand, x, y, 10, 0); !found {
+			return 0, 0, false
+		}
+	}
+	if !zone.isInOpenRegion(x, y) {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// pickScenarioJob picks a job by weight, or any job if there are no weights.
+// jobs is sortedJobs(weights), it's passed in so callers can sort once.
+func pickScenarioJob(rng *rand.Rand, weights map[string]float64, jobs []string) string {
+	if len(jobs) == 0 {
+		return gotchiJobs[rng.Intn(len(gotchiJobs))]
+	}
+
+	total := 0.0
+	for _, job := range jobs {
+		total += weights[job]
+	}
+
+	roll := rng.Float64() * total
+	for _, job := range jobs {
+		roll -= weights[job]
+		if roll < 0 {
+			return job
+		}
+	}
+	return jobs[len(jobs)-1]
+}
+
+// applyScenarioStats sets a population's stat overrides on something it spawned
+func applyScenarioStats(e interfaces.IEntity, overrides map[string]float64) {
+	stats, ok := e.(interfaces.IStats)
+	if !ok {
+		return
+	}
+	for stat, value := range overrides {
+		stats.SetStat(stat, value)
+	}
+}
+
+// applyScenarioSelection sets how something a population spawned picks its
+// actions, leaving the plan's defaults for anything not given
+func applyScenarioSelection(e interfaces.IEntity, mode action.SelectionMode, temperature float64) {
+	var plan *action.ActionPlan
+	switch planner := e.(type) {
+	case *entity.Gotchi:
+		plan = &planner.ActionPlan
+	case *entity.Lickquidator:
+		plan = &planner.ActionPlan
+	default:
+		return
+	}
+	if mode != "" {
+		plan.SelectionMode = mode
+	}
+	if temperature > 0 {
+		plan.Temperature = temperature
+	}
+}
+
+// pickScenarioGotchi picks subgraph data for one of the population's gotchi ids
+func (wm *WorldManager) pickScenarioGotchi(pop *Population, gotchiData map[string]web3.SubgraphGotchiData) web3.SubgraphGotchiData {
+	if len(pop.GotchiIDs) == 0 {
+		return web3.DefaultSubgraphGotchiData
+	}
+
+	id := pop.GotchiIDs[wm.Rand.Intn(len(pop.GotchiIDs))]
+	if data, ok := gotchiData[id]; ok {
+		return data
+	}
+	return web3.DefaultSubgraphGotchiData
+}
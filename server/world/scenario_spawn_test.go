@@ -0,0 +1,77 @@
+package world
+
+import (
+	"errors"
+	"testing"
+	"thereaalm/types"
+	"thereaalm/web3"
+)
+
+func TestIsInOneZone(t *testing.T) {
+	wm := NewWorldManager(1, 5, testManifestPath, "")
+	zx, zy := wm.Zones[42].GetPosition()
+	altar := types.CenteredFootprint(5)
+
+	tests := []struct {
+		name string
+		x, y int
+		want bool
+	}{
+		{"well inside", zx + ZoneTiles/2, zy + ZoneTiles/2, true},
+		{"touching the west edge", zx + 2, zy + ZoneTiles/2, true},
+		{"touching the south edge", zx + ZoneTiles/2, zy + ZoneTiles - 3, true},
+		{"over the west edge", zx + 1, zy + ZoneTiles/2, false},
+		{"over the south edge", zx + ZoneTiles/2, zy + ZoneTiles - 2, false},
+		{"over a corner", zx + ZoneTiles - 1, zy + ZoneTiles - 1, false},
+		{"off the map", 1, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wm.isInOneZone(altar.RectAt(tt.x, tt.y)); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSpawnsStayInOneZone tries to put an altar over every zone's west edge,
+// wherever there's room, and expects to be refused
+func TestSpawnsStayInOneZone(t *testing.T) {
+	wm := NewWorldManager(1, 5, testManifestPath, "")
+
+	tried := 0
+	for _, zone := range wm.Zones {
+		zx, zy := zone.GetPosition()
+		for y := zy + 2; y < zy+ZoneTiles-2; y++ {
+			bounds := types.CenteredFootprint(5).RectAt(zx+1, y)
+			if !wm.IsAreaAvailable(bounds) || wm.getZoneForPosition(bounds.Min.X, y) == nil {
+				continue
+			}
+			tried++
+			_, err := wm.spawnEntity(wm.Rand, "altar", zx+1, y, web3.DefaultSubgraphGotchiData, "")
+			if !errors.Is(err, errFootprintBlocked) {
+				t.Fatalf("altar at %d,%d: got %v, want errFootprintBlocked", zx+1, y, err)
+			}
+			break
+		}
+	}
+	if tried == 0 {
+		t.Fatal("no free spot over any zone edge")
+	}
+
+	// and one that fits still spawns
+	zone := wm.Zones[42]
+	zx, zy := zone.GetPosition()
+	x, y, found := wm.FindNearbyAvailablePosition(wm.Rand, zx+ZoneTiles/2, zy+ZoneTiles/2, 20, 2)
+	if !found {
+		t.Fatal("no room for an altar")
+	}
+	e, err := wm.spawnEntity(wm.Rand, "altar", x, y, web3.DefaultSubgraphGotchiData, "")
+	if err != nil {
+		t.Fatalf("got %v for an altar inside a zone", err)
+	}
+	if e.GetZone() != zone {
+		t.Fatal("altar isn't in the zone holding its anchor")
+	}
+}
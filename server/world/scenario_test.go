@@ -0,0 +1,161 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"thereaalm/action"
+	"thereaalm/entity"
+)
+
+// scenarioWith wraps one population's JSON in a scenario for zone 42
+func scenarioWith(population string) []byte {
+	return []byte(fmt.Sprintf(`{"zones": [{"zoneId": 42, "populations": [%s]}]}`, population))
+}
+
+func TestScenarioSelectionSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		population string
+		wantPath   string // "" for a valid scenario
+	}{
+		{"highest", `{"type": "gotchi", "count": 1, "selectionMode": "highest"}`, ""},
+		{"softmax with a temperature", `{"type": "lickquidator", "count": 1, "selectionMode": "softmax", "temperature": 0.4}`, ""},
+		{"unknown mode", `{"type": "gotchi", "count": 1, "selectionMode": "best"}`, "zones[0].populations[0].selectionMode"},
+		{"negative temperature", `{"type": "gotchi", "count": 1, "temperature": -1}`, "zones[0].populations[0].temperature"},
+		{"on something that doesn't pick actions", `{"type": "kekwoodtree", "count": 1, "selectionMode": "highest"}`, "zones[0].populations[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScenario("test.json", scenarioWith(tt.population))
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			var errs ScenarioErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("got %v, want one error at %s", err, tt.wantPath)
+			}
+			if errs[0].Path != tt.wantPath {
+				t.Fatalf("error at %q, want %q", errs[0].Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+// TestScenarioSelectionApplied spawns gotchis set to always take their best
+// action, both at startup and from their spawner
+func TestScenarioSelectionApplied(t *testing.T) {
+	scenario, err := ParseScenario("test.json", scenarioWith(
+		`{"type": "gotchi", "count": 3, "selectionMode": "highest", "temperature": 0.4, "spawner": {"spawnRate": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pop := &scenario.Zones[0].Populations[0]
+
+	wm := NewWorldManager(1, 6, testManifestPath, "")
+	spawned := wm.spawnPopulation(wm.Zones[42].(*Zone), pop, nil)
+	if len(spawned) == 0 {
+		t.Fatal("nothing spawned")
+	}
+	for _, e := range spawned {
+		plan := e.(*entity.Gotchi).ActionPlan
+		if plan.SelectionMode != action.SelectHighest || plan.Temperature != 0.4 {
+			t.Fatalf("spawned with %q at %g", plan.SelectionMode, plan.Temperature)
+		}
+	}
+
+	rule := pop.newSpawnRule()
+	if rule.SelectionMode != action.SelectHighest || rule.Temperature != 0.4 {
+		t.Fatalf("spawn rule has %q at %g", rule.SelectionMode, rule.Temperature)
+	}
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	type wantErr struct {
+		path string
+		msg  string // start of the message
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want []wantErr
+	}{
+		{"valid", `{"zones": [{"zoneId": 1, "populations": [{"type": "gotchi", "count": 2}]}]}`, nil},
+		{"syntax", "{\n  \"zones\": [\n    {\"zoneId\": 1,,}\n  ]\n}", []wantErr{
+			{"", "line 3, column 19: invalid character ','"},
+		}},
+		{"wrong type", "{\"zones\": [\n  {\"zoneId\": 1, \"populations\": [\n    {\"type\": \"gotchi\", \"count\": \"two\"}\n  ]}\n]}", []wantErr{
+			{"zones[0].populations[0].count", "line 3, column 38: expected int but got string"},
+		}},
+		{"unknown field", "{\"zones\": [\n  {\"zoneId\": 1, \"popluations\": []}\n]}", []wantErr{
+			{"", `line 2, column 17: json: unknown field "popluations"`},
+		}},
+		{"no zones", `{"zones": []}`, []wantErr{
+			{"zones", "must list at least one zone"},
+		}},
+		{"every problem at once", `{"zones": [
+			{"zoneId": -1, "populations": [
+				{"type": "dragon", "count": -3},
+				{"type": "kekwoodtree", "count": 1, "jobs": {"farmer": 1}, "stats": {"pulse": 1}}
+			]},
+			{"zoneId": -1, "populations": [
+				{"type": "gotchi", "count": 1, "jobs": {"wizard": 1, "farmer": -1}, "stats": {"luck": 1}},
+				{"type": "gotchi", "count": 1, "spawner": {"spawnRate": 0, "maxDensity": 2}},
+				{"type": "gotchi", "count": 1, "spawner": {"spawnRate": 1}}
+			]}
+		]}`, []wantErr{
+			{"zones[0].zoneId", "must not be negative"},
+			{"zones[0].populations[0].type", `unknown entity type "dragon"`},
+			{"zones[0].populations[0].count", "must not be negative"},
+			{"zones[0].populations[1].jobs", "only gotchis have jobs"},
+			{"zones[0].populations[1].stats", "kekwoodtree has no stats"},
+			{"zones[1].zoneId", "must not be negative"},
+			{"zones[1].zoneId", "zone -1 is already listed at zones[0]"},
+			{"zones[1].populations[0].jobs.farmer", "weight must not be negative"},
+			{"zones[1].populations[0].jobs.wizard", "unknown job"},
+			{"zones[1].populations[0].jobs", "weights must add up to more than zero"},
+			{"zones[1].populations[0].stats.luck", "unknown stat"},
+			{"zones[1].populations[1].spawner.spawnRate", "must be positive"},
+			{"zones[1].populations[1].spawner.densityRadius", "must be set when maxDensity is"},
+			{"zones[1].populations[2].spawner", "gotchi already has a spawner at zones[1].populations[1]"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScenario("test.json", []byte(tt.json))
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			var got ScenarioErrors
+			var single *ScenarioError
+			switch {
+			case errors.As(err, &got):
+			case errors.As(err, &single):
+				got = ScenarioErrors{single}
+			default:
+				t.Fatalf("got %v, want scenario errors", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(got), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if got[i].File != "test.json" || got[i].Path != want.path || !strings.HasPrefix(got[i].Msg, want.msg) {
+					t.Errorf("error %d is %q, want %s: %s...", i, got[i], want.path, want.msg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package world
+
+import (
+	"container/heap"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"thereaalm/interfaces"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// event ids are unique across every scheduler so they survive being moved
+// from one zone to another
+var lastEventID atomic.Uint64
+
+type scheduledEvent struct {
+	id       interfaces.EventID
+	at       time.Duration // game time it's due
+	priority int
+	seq      uint64        // schedule order, breaks ties deterministically
+	interval time.Duration // > 0 for repeating events
+	owner    uuid.UUID
+	fn       func()
+
+	index     int  // position in the heap, -1 while it's out of it
+	cancelled bool // set if cancelled after being taken off the heap
+}
+
+// eventHeap orders events by due time, then priority, then schedule order
+type eventHeap []*scheduledEvent
+
+func (h eventHeap) Len() int { return len(h) }
+func (h eventHeap) Less(i, j int) bool {
+	if h[i].at != h[j].at {
+		return h[i].at < h[j].at
+	}
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h eventHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *eventHeap) Push(x interface{}) {
+	ev := x.(*scheduledEvent)
+	ev.index = len(*h)
+	*h = append(*h, ev)
+}
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	ev := old[n-1]
+	old[n-1] = nil
+	ev.index = -1
+	*h = old[:n-1]
+	return ev
+}
+
+// Scheduler is a game time event queue. The world has one for world systems
+// (run at the start of each tick, before any zone) and every zone has one for
+// its entities (run at the start of the zone's update). Scheduling and
+// cancelling are O(log n) so tens of thousands of pending events are fine.
+//
+// Events due in the same tick run in priority order, then due time, then the
+// order they were scheduled in. Callbacks run without the lock held so they
+// can schedule and cancel freely.
+type Scheduler struct {
+	now func() time.Duration
+
+	mu      sync.Mutex
+	events  eventHeap
+	byID    map[interfaces.EventID]*scheduledEvent
+	byOwner map[uuid.UUID]map[interfaces.EventID]*scheduledEvent
+	seq     uint64
+}
+
+func NewScheduler(now func() time.Duration) *Scheduler {
+	return &Scheduler{
+		now:     now,
+		byID:    make(map[interfaces.EventID]*scheduledEvent),
+		byOwner: make(map[uuid.UUID]map[interfaces.EventID]*scheduledEvent),
+	}
+}
+
+// ScheduleAt runs fn once at game time at. Times already passed run next time
+// the scheduler does.
+func (s *Scheduler) ScheduleAt(at time.Duration, priority int, owner uuid.UUID, fn func()) interfaces.EventID {
+	return s.add(at, 0, priority, owner, fn)
+}
+
+// ScheduleAfter runs fn once, delay from now
+func (s *Scheduler) ScheduleAfter(delay time.Duration, priority int, owner uuid.UUID, fn func()) interfaces.EventID {
+	return s.add(s.now()+delay, 0, priority, owner, fn)
+}
+
+// ScheduleRepeating runs fn every interval from now until it's cancelled. If
+// the interval is shorter than a tick it runs as many times as it's due.
+func (s *Scheduler) ScheduleRepeating(interval time.Duration, priority int, owner uuid.UUID, fn func()) interfaces.EventID {
+	if interval <= 0 {
+		log.Println("WARNING: Repeating event interval must be positive, ignoring:", interval)
+		return 0
+	}
+	return s.add(s.now()+interval, interval, priority, owner, fn)
+}
+
+func (s *Scheduler) add(at, interval time.Duration, priority int, owner uuid.UUID, fn func()) interfaces.EventID {
+	ev := &scheduledEvent{
+		id:       interfaces.EventID(lastEventID.Add(1)),
+		at:       at,
+		priority: priority,
+		interval: interval,
+		owner:    owner,
+		fn:       fn,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.push(ev)
+	return ev.id
+}
+
+// push registers ev and puts it on the heap, callers hold the lock
+func (s *Scheduler) push(ev *scheduledEvent) {
+	s.seq++
+	ev.seq = s.seq
+	heap.Push(&s.events, ev)
+
+	s.byID[ev.id] = ev
+	if ev.owner != uuid.Nil {
+		owned := s.byOwner[ev.owner]
+		if owned == nil {
+			owned = make(map[interfaces.EventID]*scheduledEvent)
+			s.byOwner[ev.owner] = owned
+		}
+		owned[ev.id] = ev
+	}
+}
+
+// forget unregisters ev, it has to be off the heap already. Callers hold the lock.
+func (s *Scheduler) forget(ev *scheduledEvent) {
+	delete(s.byID, ev.id)
+	if owned := s.byOwner[ev.owner]; owned != nil {
+		delete(owned, ev.id)
+		if len(owned) == 0 {
+			delete(s.byOwner, ev.owner)
+		}
+	}
+}
+
+// Cancel stops an event from running (again). It returns false if the event
+// isn't pending, e.g. it already ran or belongs to another scheduler.
+func (s *Scheduler) Cancel(id interfaces.EventID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev, ok := s.byID[id]
+	if !ok {
+		return false
+	}
+	s.cancel(ev)
+	return true
+}
+
+func (s *Scheduler) cancel(ev *scheduledEvent) {
+	if ev.index >= 0 {
+		heap.Remove(&s.events, ev.index)
+	}
+	ev.cancelled = true
+	s.forget(ev)
+}
+
+// CancelOwner cancels every event belonging to owner and returns how many there were
+func (s *Scheduler) CancelOwner(owner uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := s.byOwner[owner]
+	count := len(owned)
+	for _, ev := range owned {
+		s.cancel(ev)
+	}
+	return count
+}
+
+// IsScheduled reports whether an event is still pending. A repeating event
+// stays scheduled until it's cancelled.
+func (s *Scheduler) IsScheduled(id interfaces.EventID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.byID[id]
+	return ok
+}
+
+// Len returns the number of pending events
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.byID)
+}
+
+// RunDue runs every event due at or before now and returns how many ran.
+// Events that come due while it's running (including repeats) run too.
+func (s *Scheduler) RunDue(now time.Duration) int {
+	ran := 0
+	for {
+		batch := s.takeDue(now)
+		if len(batch) == 0 {
+			return ran
+		}
+
+		for _, ev := range batch {
+			if !s.begin(ev) {
+				continue
+			}
+			ev.fn()
+			ran++
+			s.finish(ev)
+		}
+	}
+}
+
+// takeDue pops everything due by now in run order
+func (s *Scheduler) takeDue(now time.Duration) []*scheduledEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var batch []*scheduledEvent
+	for len(s.events) > 0 && s.events[0].at <= now {
+		batch = append(batch, heap.Pop(&s.events).(*scheduledEvent))
+	}
+
+	sort.Slice(batch, func(i, j int) bool {
+		if batch[i].priority != batch[j].priority {
+			return batch[i].priority < batch[j].priority
+		}
+		if batch[i].at != batch[j].at {
+			return batch[i].at < batch[j].at
+		}
+		return batch[i].seq < batch[j].seq
+	})
+	return batch
+}
+
+// begin returns false if ev was cancelled by an earlier callback. One-shot
+// events are unregistered before they run.
+func (s *Scheduler) begin(ev *scheduledEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev.cancelled {
+		return false
+	}
+	if ev.interval == 0 {
+		s.forget(ev)
+	}
+	return true
+}
+
+// finish puts a repeating event back on the heap unless its callback cancelled it
+func (s *Scheduler) finish(ev *scheduledEvent) {
+	if ev.interval == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev.cancelled {
+		return
+	}
+	ev.at += ev.interval
+	s.forget(ev)
+	s.push(ev)
+}
+
+// transferOwner moves owner's pending events to another scheduler, keeping
+// their ids. It's used when an entity migrates, so never while either
+// scheduler is running.
+func (s *Scheduler) transferOwner(owner uuid.UUID, to *Scheduler) {
+	if s == to || owner == uuid.Nil {
+		return
+	}
+
+	s.mu.Lock()
+	owned := make([]*scheduledEvent, 0, len(s.byOwner[owner]))
+	for _, ev := range s.byOwner[owner] {
+		if ev.index >= 0 {
+			heap.Remove(&s.events, ev.index)
+		}
+		s.forget(ev)
+		owned = append(owned, ev)
+	}
+	s.mu.Unlock()
+
+	if len(owned) == 0 {
+		return
+	}
+
+	// keep their relative order on the way in
+	sort.Slice(owned, func(i, j int) bool {
+		return eventHeap(owned).Less(i, j)
+	})
+
+	to.mu.Lock()
+	defer to.mu.Unlock()
+
+	for _, ev := range owned {
+		to.push(ev)
+	}
+}
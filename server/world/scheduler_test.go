@@ -0,0 +1,123 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testScheduler runs on a clock the test moves by hand
+func testScheduler() (*Scheduler, *time.Duration) {
+	now := new(time.Duration)
+	return NewScheduler(func() time.Duration { return *now }), now
+}
+
+func TestSchedulerRunOrder(t *testing.T) {
+	type event struct {
+		name     string
+		at       time.Duration
+		priority int
+	}
+
+	tests := []struct {
+		name   string
+		events []event // in the order they're scheduled
+		now    time.Duration
+		want   []string
+	}{
+		{"by priority first", []event{
+			{"late but urgent", 3, 0}, {"early", 1, 5}, {"middling", 2, 1},
+		}, 10, []string{"late but urgent", "middling", "early"}},
+		{"then due time", []event{
+			{"c", 3, 1}, {"a", 1, 1}, {"b", 2, 1},
+		}, 10, []string{"a", "b", "c"}},
+		{"then schedule order", []event{
+			{"first", 2, 1}, {"second", 2, 1}, {"third", 2, 1},
+		}, 10, []string{"first", "second", "third"}},
+		{"only what's due", []event{
+			{"due", 5, 0}, {"not yet", 6, 0}, {"overdue", -1, 1},
+		}, 5, []string{"due", "overdue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := testScheduler()
+			var ran []string
+			for _, ev := range tt.events {
+				name := ev.name
+				s.ScheduleAt(ev.at, ev.priority, uuid.Nil, func() { ran = append(ran, name) })
+			}
+
+			if n := s.RunDue(tt.now); n != len(tt.want) || !reflect.DeepEqual(ran, tt.want) {
+				t.Fatalf("ran %d: %v, want %v", n, ran, tt.want)
+			}
+			if s.Len() != len(tt.events)-len(tt.want) {
+				t.Fatalf("%d events left, want %d", s.Len(), len(tt.events)-len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSchedulerRepeatsAndCancels(t *testing.T) {
+	s, now := testScheduler()
+	var ran []string
+
+	*now = 10
+	tick := s.ScheduleRepeating(3, 0, uuid.Nil, func() { ran = append(ran, "tick") })
+	victim := 0
+	s.ScheduleAfter(2, 1, uuid.Nil, func() {
+		ran = append(ran, "cancel")
+		s.Cancel(tick)
+	})
+	victimID := s.ScheduleAfter(4, 0, uuid.Nil, func() { victim++ })
+	s.ScheduleAfter(1, 0, uuid.Nil, func() { s.Cancel(victimID) })
+
+	// by 16 the tick due at 13 goes before the priority 1 cancel due at 12,
+	// which then stops the repeat it queued for 16
+	s.RunDue(11)
+	s.RunDue(16)
+	if want := []string{"tick", "cancel"}; !reflect.DeepEqual(ran, want) {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+	if victim != 0 || s.IsScheduled(victimID) || s.IsScheduled(tick) || s.Len() != 0 {
+		t.Fatalf("cancelled events still around: victim ran %d times, %d pending", victim, s.Len())
+	}
+}
+
+func TestSchedulerTransferOwner(t *testing.T) {
+	from, _ := testScheduler()
+	to, _ := testScheduler()
+	mover, stayer := uuid.UUID{1}, uuid.UUID{2}
+	var ran []string
+	run := func(name string) func() { return func() { ran = append(ran, name) } }
+
+	to.ScheduleAt(2, 0, uuid.Nil, run("already there"))
+	second := from.ScheduleAt(2, 0, mover, run("mover second"))
+	from.ScheduleAt(1, 0, mover, run("mover first"))
+	from.ScheduleAt(2, 0, stayer, run("stayer"))
+	repeat := from.ScheduleRepeating(5, 0, mover, run("mover repeat"))
+
+	from.transferOwner(mover, to)
+
+	if from.Len() != 1 || to.Len() != 4 {
+		t.Fatalf("%d events left behind and %d moved over, want 1 and 4", from.Len(), to.Len())
+	}
+	if !to.IsScheduled(second) || from.IsScheduled(second) {
+		t.Fatal("moved event didn't keep its id")
+	}
+
+	// moved events keep their order, and go after what was already there at the same time
+	to.RunDue(5)
+	if want := []string{"mover first", "already there", "mover second", "mover repeat"}; !reflect.DeepEqual(ran, want) {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+
+	if !to.Cancel(repeat) || to.CancelOwner(mover) != 0 {
+		t.Fatal("repeating event wasn't the mover's last one on its new scheduler")
+	}
+	if from.CancelOwner(mover) != 0 || from.CancelOwner(stayer) != 1 {
+		t.Fatal("owners' events were mixed up")
+	}
+}
@@ -0,0 +1,94 @@
+package world
+
+import (
+	"context"
+	"log"
+	"thereaalm/utils"
+	"time"
+)
+
+// WorldState is everything we save when the world shuts down
+type WorldState struct {
+	Seed       int64           `json:"seed"`
+	Tick       uint64          `json:"tick"`
+	GameTime_s float64         `json:"gameTime_s"`
+	SavedAt    time.Time       `json:"savedAt"`
+	Zones      []*ZoneSnapshot `json:"zones"`
+}
+
+// Persister saves the world's state somewhere that outlives the process,
+// giving up once ctx is done
+type Persister interface {
+	SaveWorldState(ctx context.Context, state *WorldState) error
+}
+
+// CaptureState copies the whole world, it must only be called between ticks
+func (wm *WorldManager) CaptureState() *WorldState {
+	tick := wm.GetTick()
+
+	zones := make([]*Zone, 0, len(wm.Zones))
+	for _, z := range wm.Zones {
+		if zone, ok := z.(*Zone); ok {
+			zones = append(zones, zone)
+		}
+	}
+
+	snapshots := make([]*ZoneSnapshot, len(zones))
+	for i, zone := range zones {
+		snapshots[i] = zone.buildSnapshot(tick)
+	}
+
+	return &WorldState{
+		Seed:       wm.Seed,
+		Tick:       tick,
+		GameTime_s: wm.GameTime.Seconds(),
+		SavedAt:    time.Now(),
+		Zones:      snapshots,
+	}
+}
+
+// Shutdown stops the world in order: new commands are rejected, Run()'s loop
+// stops once the current tick is done, the state is handed to persister (if
+// there is one) and the worker pool is drained. Everything has to happen
+// before ctx is done, otherwise ctx's error is returned.
+func (wm *WorldManager) Shutdown(ctx context.Context, persister Persister) error {
+	log.Println("Shutting down world...")
+
+	// queued commands fail now rather than waiting on a tick that won't come
+	wm.closeCommands()
+
+	wm.controlMu.Lock()
+	stop, done := wm.stopLoop, wm.loopDone
+	wm.stopLoop = nil
+	wm.controlMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// hold the step lock so nobody can step the world while it's saved
+	wm.stepMu.Lock()
+	wm.stopped = true
+	defer wm.stepMu.Unlock()
+
+	if persister != nil {
+		state := wm.CaptureState()
+		if err := persister.SaveWorldState(ctx, state); err != nil {
+			log.Printf("ERROR [%s]: Failed to save world state: %v", utils.GetFuncName(), err)
+			return err
+		}
+		log.Printf("Saved world state at tick %d", state.Tick)
+	}
+
+	if err := wm.pool.Drain(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("World shut down at tick %d", wm.GetTick())
+	return nil
+}
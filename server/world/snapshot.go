@@ -0,0 +1,175 @@
+package world
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"thereaalm/calendar"
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/types"
+	"thereaalm/utils"
+	"thereaalm/weather"
+
+	"github.com/google/uuid"
+)
+
+// snapshotKeepAliveTicks is how many ticks a zone keeps publishing snapshots
+// after the last time someone asked for one
+const snapshotKeepAliveTicks = 30
+
+// ZoneSnapshot is an immutable copy of a zone taken at the end of a tick.
+// Nothing in it points at live entity state so it can be read from anywhere.
+type ZoneSnapshot struct {
+	Tick            uint64           `json:"tick"`
+	EntitySnapshots []EntitySnapshot `json:"entitySnapshots"`
+	ThreatLevel     int              `json:"threatlevel"`
+	Date            calendar.Date    `json:"date"`
+	Weather         weather.State    `json:"weather"`
+}
+
+// EntitySnapshot captures the position, type and snapshot data of an entity.
+// Data is encoded when the snapshot is taken so later changes can't leak in.
+// Entities on the move also get the tiles they have left to walk and how long
+// that'll take, so clients can interpolate.
+type EntitySnapshot struct {
+	ID        uuid.UUID           `json:"id"`
+	ZoneID    int                 `json:"zoneId"`
+	Type      string              `json:"type"`
+	X         int                 `json:"tileX"`
+	Y         int                 `json:"tileY"`
+	Footprint *types.Footprint    `json:"footprint,omitempty"` // Only for entities bigger than a tile
+	Path      []pathfinding.Point `json:"path,omitempty"`
+	ETA_s     float64             `json:"eta_s,omitempty"`
+	Data      json.RawMessage     `json:"data"`
+}
+
+// zoneSnapshots holds a zone's published snapshot and who's waiting on it
+type zoneSnapshots struct {
+	current    atomic.Pointer[ZoneSnapshot]
+	wantedTick atomic.Uint64 // Tick of the last request, 0 = never asked
+
+	mu        sync.Mutex
+	published chan struct{} // Closed (and replaced) on every publish
+}
+
+// waitChan returns a channel that is closed on the next publish
+func (s *zoneSnapshots) waitChan() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.published == nil {
+		s.published = make(chan struct{})
+	}
+	return s.published
+}
+
+func (s *zoneSnapshots) publish(snapshot *ZoneSnapshot) {
+	s.current.Store(snapshot)
+
+	s.mu.Lock()
+	if s.published != nil {
+		close(s.published)
+		s.published = nil
+	}
+	s.mu.Unlock()
+}
+
+// isWanted reports whether anyone has asked for this zone recently
+func (s *zoneSnapshots) isWanted(tick uint64) bool {
+	wanted := s.wantedTick.Load()
+	return wanted != 0 && wanted+snapshotKeepAliveTicks >= tick
+}
+
+// buildSnapshot copies the zone's current state, it must only be called
+// between ticks while nothing is updating
+func (z *Zone) buildSnapshot(tick uint64) *ZoneSnapshot {
+	snapshot := &ZoneSnapshot{
+		Tick:            tick,
+		EntitySnapshots: make([]EntitySnapshot, 0, len(z.Entities)),
+		ThreatLevel:     z.ThreatLevel,
+		Date:            z.WorldManager.GetDate(),
+		Weather:         z.GetWeather(),
+	}
+
+	for _, entity := range z.Entities {
+		snapshot.EntitySnapshots = append(snapshot.EntitySnapshots, newEntitySnapshot(z.ID, entity))
+	}
+
+	return snapshot
+}
+
+// newEntitySnapshot copies one entity, it has the same rules as buildSnapshot
+func newEntitySnapshot(zoneID int, entity interfaces.IEntity) EntitySnapshot {
+	data, err := json.Marshal(entity.GetSnapshotData())
+	if err != nil {
+		log.Printf("ERROR [%s]: Failed to encode snapshot data for %s %s: %v",
+			utils.GetFuncName(), entity.GetType(), entity.GetUUID(), err)
+		data = json.RawMessage("null")
+	}
+
+	x, y := entity.GetPosition()
+	snapshot := EntitySnapshot{
+		ID:     entity.GetUUID(),
+		ZoneID: zoneID,
+		Type:   entity.GetType(),
+		X:      x,
+		Y:      y,
+		Data:   data,
+	}
+
+	if footprint := entity.GetFootprint(); footprint != types.SingleTile {
+		snapshot.Footprint = &footprint
+	}
+	if mover, ok := entity.(interfaces.IMover); ok && mover.IsMoving() {
+		snapshot.Path = append([]pathfinding.Point(nil), mover.GetPath()...)
+		snapshot.ETA_s = mover.GetETA_s(mover.GetMoveSpeed())
+	}
+	return snapshot
+}
+
+// publishSnapshots rebuilds the snapshot of every zone someone is watching.
+// Zones nobody has asked about recently cost nothing.
+func (wm *WorldManager) publishSnapshots(tick uint64) {
+	var wanted []*Zone
+	for _, z := range wm.Zones {
+		if zone, ok := z.(*Zone); ok && zone.snapshots.isWanted(tick) {
+			wanted = append(wanted, zone)
+		}
+	}
+	if len(wanted) == 0 {
+		return
+	}
+
+	wm.forEachZone(wanted, func(zone *Zone) {
+		zone.snapshots.publish(zone.buildSnapshot(tick))
+	})
+}
+
+// GetZoneSnapshot returns the snapshot of a zone for the current tick. If the
+// zone hasn't been watched recently it waits for the next tick to publish one.
+func (wm *WorldManager) GetZoneSnapshot(ctx context.Context, zoneID int) (*ZoneSnapshot, error) {
+	zone, ok := wm.GetZoneByID(zoneID).(*Zone)
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+
+	// grab the wait channel before checking so we can't miss a publish
+	published := zone.snapshots.waitChan()
+
+	tick := wm.GetTick()
+	zone.snapshots.wantedTick.Store(tick + 1)
+
+	if snapshot := zone.snapshots.current.Load(); snapshot != nil && snapshot.Tick >= tick {
+		return snapshot, nil
+	}
+
+	select {
+	case <-published:
+		return zone.snapshots.current.Load(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -1,47 +1,86 @@
 package world
 
 import (
-	"fmt"
+	"sort"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/utils"
+
+	"github.com/google/uuid"
 )
 
+// CellKey identifies a cell of the spatial hash
+type CellKey struct {
+    X, Y int
+}
+
 // SpatialHash represents the spatial partitioning system within a zone
 // - IMPORTANT: all coordinates are in world space
+// - queries walk cells in row order and each cell in insertion order, so
+//   results are deterministic and every entity is seen at most once
 type SpatialHash struct {
     CellSize     int
-    HashTable    map[string][]interfaces.IEntity
-    entityToCell map[string]string // Maps entity UUID to its last known cell
+    HashTable    map[CellKey][]interfaces.IEntity
+    entityToCell map[uuid.UUID]CellKey // Maps entity UUID to its last known cell
+
+    // bounds of every cell that's ever held an entity, so huge queries only
+    // walk cells that could have something in them
+    minCell, maxCell CellKey
+    hasCells         bool
+
+    // footprintReach is the furthest any entity's footprint has stretched from
+    // its anchor, occupancy checks look that far for entities covering a tile
+    footprintReach int
 }
 
 // NewSpatialHash creates a new spatial hash with the given cell size
 func NewSpatialHash(cellSize int) *SpatialHash {
     return &SpatialHash{
         CellSize:     cellSize,
-        HashTable:    make(map[string][]interfaces.IEntity),
-        entityToCell: make(map[string]string),
+        HashTable:    make(map[CellKey][]interfaces.IEntity),
+        entityToCell: make(map[uuid.UUID]CellKey),
     }
 }
 
-// hashCoordinates generates a unique key for a cell
-func (sh *SpatialHash) hashCoordinates(x, y int) string {
-    cellX := x / sh.CellSize
-    cellY := y / sh.CellSize
-    return fmt.Sprintf("%d,%d", cellX, cellY)
+// cellFor returns the cell a position falls in (rounding down for negatives)
+func (sh *SpatialHash) cellFor(x, y int) CellKey {
+    return CellKey{X: floorDiv(x, sh.CellSize), Y: floorDiv(y, sh.CellSize)}
+}
+
+func floorDiv(a, b int) int {
+    q := a / b
+    if a%b != 0 && (a < 0) != (b < 0) {
+        q--
+    }
+    return q
 }
 
 // Insert adds an entity to the correct cell and tracks its location
 func (sh *SpatialHash) Insert(entity interfaces.IEntity) {
     x, y := entity.GetPosition()
-    cellKey := sh.hashCoordinates(x, y)
+    cellKey := sh.cellFor(x, y)
 
     sh.HashTable[cellKey] = append(sh.HashTable[cellKey], entity)
-    sh.entityToCell[entity.GetUUID().String()] = cellKey
+    sh.entityToCell[entity.GetUUID()] = cellKey
+    sh.growBounds(cellKey)
+    sh.footprintReach = max(sh.footprintReach, entity.GetFootprint().Reach())
+}
+
+func (sh *SpatialHash) growBounds(cellKey CellKey) {
+    if !sh.hasCells {
+        sh.minCell, sh.maxCell, sh.hasCells = cellKey, cellKey, true
+        return
+    }
+    sh.minCell.X = min(sh.minCell.X, cellKey.X)
+    sh.minCell.Y = min(sh.minCell.Y, cellKey.Y)
+    sh.maxCell.X = max(sh.maxCell.X, cellKey.X)
+    sh.maxCell.Y = max(sh.maxCell.Y, cellKey.Y)
 }
 
 // Remove deletes an entity from its cell and tracking map
 func (sh *SpatialHash) Remove(entity interfaces.IEntity) {
-    uuid := entity.GetUUID().String()
-    cellKey, exists := sh.entityToCell[uuid]
+    id := entity.GetUUID()
+    cellKey, exists := sh.entityToCell[id]
     if !exists {
         return // Entity is not being tracked
     }
@@ -50,21 +89,25 @@ func (sh *SpatialHash) Remove(entity interfaces.IEntity) {
 
     // Filter out the entity
     for i, e := range entities {
-        if e.GetUUID() == entity.GetUUID() {
-            sh.HashTable[cellKey] = append(entities[:i], entities[i+1:]...)
+        if e.GetUUID() == id {
+            entities = append(entities[:i], entities[i+1:]...)
             break
         }
     }
-    delete(sh.entityToCell, uuid) // Remove from tracking map
+    if len(entities) == 0 {
+        delete(sh.HashTable, cellKey)
+    } else {
+        sh.HashTable[cellKey] = entities
+    }
+    delete(sh.entityToCell, id) // Remove from tracking map
 }
 
 // Update moves an entity if its cell changes
 func (sh *SpatialHash) Update(entity interfaces.IEntity) {
     x, y := entity.GetPosition()
-    newCell := sh.hashCoordinates(x, y)
+    newCell := sh.cellFor(x, y)
 
-    uuid := entity.GetUUID().String()
-    oldCell, exists := sh.entityToCell[uuid]
+    oldCell, exists := sh.entityToCell[entity.GetUUID()]
 
     // If the entity is new or changed cells, update its position
     if !exists || newCell != oldCell {
@@ -73,21 +116,171 @@ func (sh *SpatialHash) Update(entity interfaces.IEntity) {
     }
 }
 
-// GetEntitiesInCell retrieves all entities in the given cell
-func (sh *SpatialHash) GetEntitiesInCell(zoneX, zoneY int) []interfaces.IEntity {
-    cellKey := sh.hashCoordinates(zoneX, zoneY)
-    return sh.HashTable[cellKey]
+// GetEntitiesInCell retrieves all entities in the cell containing a position
+func (sh *SpatialHash) GetEntitiesInCell(x, y int) []interfaces.IEntity {
+    return sh.HashTable[sh.cellFor(x, y)]
 }
 
-// IsOccupied checks if a specific grid position is occupied
+// IsPositionAvailable checks no entity's footprint covers a tile
 func (sh *SpatialHash) IsPositionAvailable(x, y int) bool {
-    entities := sh.GetEntitiesInCell(x, y)
-    for _, entity := range entities {
-        ex, ey := entity.GetPosition()
-        if ex == x && ey == y {
-            return false
+    tile := pathfinding.Point{X: x, Y: y}
+    reach := sh.footprintReach
+
+    minCell := sh.cellFor(x-reach, y-reach)
+    maxCell := sh.cellFor(x+reach, y+reach)
+    for cy := minCell.Y; cy <= maxCell.Y; cy++ {
+        for cx := minCell.X; cx <= maxCell.X; cx++ {
+            for _, entity := range sh.HashTable[CellKey{X: cx, Y: cy}] {
+                ex, ey := entity.GetPosition()
+                if ex == x && ey == y {
+                    return false
+                }
+                if reach > 0 && entity.GetBounds().Contains(tile) {
+                    return false
+                }
+            }
         }
     }
     return true
 }
 
+// ForEachInRect calls fn for every entity inside the rectangle (edges
+// included). Returning false from fn stops the query.
+func (sh *SpatialHash) ForEachInRect(minX, minY, maxX, maxY int, fn func(e interfaces.IEntity) bool) {
+    if !sh.hasCells || minX > maxX || minY > maxY {
+        return
+    }
+
+    minCell := sh.cellFor(minX, minY)
+    maxCell := sh.cellFor(maxX, maxY)
+    minCell.X = max(minCell.X, sh.minCell.X)
+    minCell.Y = max(minCell.Y, sh.minCell.Y)
+    maxCell.X = min(maxCell.X, sh.maxCell.X)
+    maxCell.Y = min(maxCell.Y, sh.maxCell.Y)
+
+    for cy := minCell.Y; cy <= maxCell.Y; cy++ {
+        for cx := minCell.X; cx <= maxCell.X; cx++ {
+            for _, e := range sh.HashTable[CellKey{X: cx, Y: cy}] {
+                ex, ey := e.GetPosition()
+                if ex < minX || ex > maxX || ey < minY || ey > maxY {
+                    continue
+                }
+                if !fn(e) {
+                    return
+                }
+            }
+        }
+    }
+}
+
+// ForEachInRadius calls fn for every entity within radius of a position,
+// measured with the given metric. Returning false from fn stops the query.
+func (sh *SpatialHash) ForEachInRadius(x, y, radius int, metric interfaces.DistanceMetric, fn func(e interfaces.IEntity) bool) {
+    if radius < 0 {
+        return
+    }
+
+    sh.ForEachInRect(x-radius, y-radius, x+radius, y+radius, func(e interfaces.IEntity) bool {
+        ex, ey := e.GetPosition()
+        if !withinRadius(x-ex, y-ey, radius, metric) {
+            return true
+        }
+        return fn(e)
+    })
+}
+
+func withinRadius(dx, dy, radius int, metric interfaces.DistanceMetric) bool {
+    if metric == interfaces.Euclidean {
+        return dx*dx+dy*dy <= radius*radius
+    }
+    return utils.Abs(dx)+utils.Abs(dy) <= radius
+}
+
+type nearestCandidate struct {
+    entity interfaces.IEntity
+    dist   int
+}
+
+// FindNearest returns up to k entities matching match (nil matches
+// everything) within maxRadius of a position, closest first by Manhattan
+// distance. It searches outwards a ring of cells at a time and only calls
+// match on candidates in distance order, once nothing further out could be
+// closer, so an expensive match is only paid for until k are found.
+func (sh *SpatialHash) FindNearest(x, y, k, maxRadius int, match func(e interfaces.IEntity) bool) []interfaces.IEntity {
+    if k <= 0 || maxRadius < 0 || !sh.hasCells {
+        return nil
+    }
+
+    // only cells the search box overlaps can hold anything in range
+    center := sh.cellFor(x, y)
+    lo := sh.cellFor(x-maxRadius, y-maxRadius)
+    hi := sh.cellFor(x+maxRadius, y+maxRadius)
+    maxRing := max(center.X-lo.X, hi.X-center.X, center.Y-lo.Y, hi.Y-center.Y)
+    lo.X, lo.Y = max(lo.X, sh.minCell.X), max(lo.Y, sh.minCell.Y)
+    hi.X, hi.Y = min(hi.X, sh.maxCell.X), min(hi.Y, sh.maxCell.Y)
+
+    var nearest []interfaces.IEntity
+    var pending []nearestCandidate // In range but not matched yet
+    for ring := 0; ring <= maxRing; ring++ {
+        sh.forEachCellInRing(center, ring, lo, hi, func(entities []interfaces.IEntity) {
+            for _, e := range entities {
+                ex, ey := e.GetPosition()
+                if dist := utils.Abs(x-ex) + utils.Abs(y-ey); dist <= maxRadius {
+                    pending = append(pending, nearestCandidate{entity: e, dist: dist})
+                }
+            }
+        })
+        sort.SliceStable(pending, func(i, j int) bool { return pending[i].dist < pending[j].dist })
+
+        // anything closer than the clearance can't be beaten by a later ring
+        clearance := maxRadius + 1
+        if ring < maxRing {
+            clearance = sh.ringClearance(x, y, center, ring)
+        }
+        checked := 0
+        for ; checked < len(pending) && pending[checked].dist < clearance; checked++ {
+            if match != nil && !match(pending[checked].entity) {
+                continue
+            }
+            nearest = append(nearest, pending[checked].entity)
+            if len(nearest) == k {
+                return nearest
+            }
+        }
+        pending = pending[checked:]
+    }
+
+    return nearest
+}
+
+// ringClearance is the closest anything outside the first ring+1 rings of
+// cells around center could be to a position
+func (sh *SpatialHash) ringClearance(x, y int, center CellKey, ring int) int {
+    minX, minY := (center.X-ring)*sh.CellSize, (center.Y-ring)*sh.CellSize
+    maxX, maxY := (center.X+ring+1)*sh.CellSize-1, (center.Y+ring+1)*sh.CellSize-1
+    return min(x-minX, maxX-x, y-minY, maxY-y) + 1
+}
+
+// forEachCellInRing visits the occupied cells exactly ring cells away from
+// center (ring 0 is center itself) that lie between lo and hi, in row order
+func (sh *SpatialHash) forEachCellInRing(center CellKey, ring int, lo, hi CellKey, fn func(entities []interfaces.IEntity)) {
+    for cy := center.Y - ring; cy <= center.Y+ring; cy++ {
+        if cy < lo.Y || cy > hi.Y {
+            continue
+        }
+
+        step := 1
+        if cy != center.Y-ring && cy != center.Y+ring {
+            // middle rows only have the two end cells
+            step = max(2*ring, 1)
+        }
+        for cx := center.X - ring; cx <= center.X+ring; cx += step {
+            if cx < lo.X || cx > hi.X {
+                continue
+            }
+            if entities := sh.HashTable[CellKey{X: cx, Y: cy}]; len(entities) > 0 {
+                fn(entities)
+            }
+        }
+    }
+}
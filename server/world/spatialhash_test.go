@@ -0,0 +1,94 @@
+package world
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+	"thereaalm/entity/resourceentity"
+	"thereaalm/interfaces"
+	"thereaalm/utils"
+
+	"github.com/google/uuid"
+)
+
+// randomHash scatters bushes over a 200x200 area, some off the negative edges.
+// Each bush's id is its index, see hashIndex.
+func randomHash(seed int64, count int) (*SpatialHash, []interfaces.IEntity) {
+	rng := rand.New(rand.NewSource(seed))
+	sh := NewSpatialHash(16)
+	entities := make([]interfaces.IEntity, count)
+	for i := range entities {
+		var id uuid.UUID
+		binary.BigEndian.PutUint32(id[:], uint32(i))
+		entities[i] = resourceentity.NewFomoBerryBush(id, rng.Intn(200)-20, rng.Intn(200)-20)
+		sh.Insert(entities[i])
+	}
+	return sh, entities
+}
+
+// hashIndex is the index randomHash gave e
+func hashIndex(e interfaces.IEntity) int {
+	id := e.GetUUID()
+	return int(binary.BigEndian.Uint32(id[:]))
+}
+
+func manhattan(x, y int, e interfaces.IEntity) int {
+	ex, ey := e.GetPosition()
+	return utils.Abs(x-ex) + utils.Abs(y-ey)
+}
+
+// TestFindNearestMatchesBruteForce checks FindNearest against sorting every
+// entity by distance, ties aside
+func TestFindNearestMatchesBruteForce(t *testing.T) {
+	sh, entities := randomHash(1, 600)
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 500; i++ {
+		x, y := rng.Intn(240)-40, rng.Intn(240)-40
+		k, maxRadius := 1+rng.Intn(6), rng.Intn(60)
+		match := func(e interfaces.IEntity) bool { return hashIndex(e)%3 != 0 }
+
+		var want []int
+		for _, e := range entities {
+			if d := manhattan(x, y, e); d <= maxRadius && match(e) {
+				want = append(want, d)
+			}
+		}
+		sort.Ints(want)
+		want = want[:min(k, len(want))]
+
+		got := sh.FindNearest(x, y, k, maxRadius, match)
+		if len(got) != len(want) {
+			t.Fatalf("(%d,%d) k=%d r=%d: got %d entities, want %d", x, y, k, maxRadius, len(got), len(want))
+		}
+		for j, e := range got {
+			if d := manhattan(x, y, e); d != want[j] || !match(e) {
+				t.Fatalf("(%d,%d) k=%d r=%d: result %d is %d away, want %d", x, y, k, maxRadius, j, d, want[j])
+			}
+		}
+	}
+}
+
+// TestFindNearestMatchesLazily makes sure match is tried closest first and
+// not again once k have been found
+func TestFindNearestMatchesLazily(t *testing.T) {
+	sh, _ := randomHash(3, 600)
+	const x, y, k = 80, 80, 3
+
+	var tried []int
+	got := sh.FindNearest(x, y, k, 60, func(e interfaces.IEntity) bool {
+		tried = append(tried, manhattan(x, y, e))
+		return len(tried)%4 == 0
+	})
+
+	if len(got) != k {
+		t.Fatalf("got %d entities, want %d", len(got), k)
+	}
+	if len(tried) != 4*k {
+		t.Fatalf("match called %d times, want %d", len(tried), 4*k)
+	}
+	if !sort.IntsAreSorted(tried) {
+		t.Fatalf("match called out of distance order: %v", tried)
+	}
+}
@@ -23,12 +23,12 @@ func (sa *SpawnArea) AddPosition(x, y int) {
     sa.Positions = append(sa.Positions, [2]int{x, y})
 }
 
-// GetRandomPosition returns a random spawn position from the area
-func (sa *SpawnArea) GetRandomPosition() (int, int, bool) {
+// GetRandomPosition returns a random spawn position from the area using the given random stream
+func (sa *SpawnArea) GetRandomPosition(r *rand.Rand) (int, int, bool) {
     if len(sa.Positions) == 0 {
         return 0, 0, false
     }
-    idx := rand.Intn(len(sa.Positions))
+    idx := r.Intn(len(sa.Positions))
     pos := sa.Positions[idx]
     return pos[0], pos[1], true
 }
\ No newline at end of file
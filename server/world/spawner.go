@@ -0,0 +1,266 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"thereaalm/action"
+	"thereaalm/entity/entitystate"
+	"thereaalm/interfaces"
+	"thereaalm/utils"
+	"thereaalm/web3"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// how far from a spawn area tile we'll look for a tile that satisfies MinSpacing
+const spawnSearchRadius = 8
+
+// SpawnRule keeps the population of one entity type in a zone topped up. It
+// looks after the entities it started with and the ones it spawns wherever
+// they wander, so one that walks into the next zone isn't replaced.
+type SpawnRule struct {
+	EntityType       string
+	SpawnArea        string  // Spawn area type to spawn in, defaults to EntityType
+	TargetPopulation int     // Living members the rule should keep
+	SpawnRate        float64 // Max spawns per second of game time
+	MaxDensity       int     // Max living entities of EntityType within DensityRadius of a spawn, 0 = no limit
+	DensityRadius    int
+	MinSpacing       int                  // Empty tiles required around a spawn (FindNearbyAvailablePosition's minGap)
+	RespawnDelay_s   float64              // How long a lost entity waits before it's replaced
+	Jobs             map[string]float64   // Gotchis only, job name -> weight as for Population.Jobs
+	Stats            map[string]float64   // Stat overrides for everything spawned
+	SelectionMode    action.SelectionMode // As for Population.SelectionMode
+	Temperature      float64
+
+	jobs       []string        // Jobs in a fixed order, see sortedJobs
+	members    []uuid.UUID     // Entities this rule looks after, alive when last counted
+	population int             // Living members
+	lost       int             // Members that died or left the world since the zone last updated
+	respawnAt  []time.Duration // Game times when lost entities may be replaced
+	budget     float64         // Spawns accrued from SpawnRate but not used yet
+}
+
+// Spawner runs a zone's spawn rules every tick so populations self-balance
+type Spawner struct {
+	zone  *Zone
+	Rules []*SpawnRule
+
+	gotchisSpawned int // For giving each spawned gotchi its own token id
+}
+
+func NewSpawner(zone *Zone) *Spawner {
+	return &Spawner{
+		zone:  zone,
+		Rules: make([]*SpawnRule, 0),
+	}
+}
+
+// AddRule registers a spawn rule. The members (usually what the scenario just
+// spawned) count towards its target so it only makes up the difference.
+func (s *Spawner) AddRule(rule *SpawnRule, members []interfaces.IEntity) {
+	if rule.SpawnArea == "" {
+		rule.SpawnArea = rule.EntityType
+	}
+	rule.jobs = sortedJobs(rule.Jobs)
+	for _, e := range members {
+		rule.members = append(rule.members, e.GetUUID())
+	}
+	rule.population = len(rule.members)
+	s.Rules = append(s.Rules, rule)
+}
+
+// countPopulations drops members that have died or left the world. Members
+// can be in any zone so this has to run between ticks, not in a zone update.
+func (s *Spawner) countPopulations() {
+	for _, rule := range s.Rules {
+		living := rule.members[:0]
+		for _, id := range rule.members {
+			e := s.zone.WorldManager.GetEntityByUUID(id)
+			if e == nil || isDead(e) {
+				rule.lost++
+				continue
+			}
+			living = append(living, id)
+		}
+		rule.members = living
+		rule.population = len(living)
+	}
+}
+
+// countSpawnerPopulations recounts every zone's spawn rules, it must only be
+// called from Step() before the zones update
+func (wm *WorldManager) countSpawnerPopulations() {
+	for _, z := range wm.Zones {
+		if zone, ok := z.(*Zone); ok && zone.Spawner != nil {
+			zone.Spawner.countPopulations()
+		}
+	}
+}
+
+// Update tops up every rule's population. It runs inside the zone's update so
+// it only touches this zone and uses the zone's random stream.
+func (s *Spawner) Update(dt_s float64) {
+	if len(s.Rules) == 0 {
+		return
+	}
+
+	now := s.zone.WorldManager.Now()
+	for _, rule := range s.Rules {
+		s.updateRule(rule, dt_s, now)
+	}
+}
+
+func (s *Spawner) updateRule(rule *SpawnRule, dt_s float64, now time.Duration) {
+	population := rule.population
+
+	// anything lost since last tick can be replaced once the delay is up
+	for ; rule.lost > 0; rule.lost-- {
+		rule.respawnAt = append(rule.respawnAt, now+time.Duration(rule.RespawnDelay_s*float64(time.Second)))
+	}
+
+	deficit := rule.TargetPopulation - population
+	if deficit <= 0 {
+		rule.respawnAt = rule.respawnAt[:0]
+		rule.budget = 0
+		return
+	}
+
+	// the part of the deficit that isn't waiting on a respawn delay can spawn now
+	waiting := 0
+	for _, at := range rule.respawnAt {
+		if at > now {
+			waiting++
+		}
+	}
+	ready := deficit - waiting
+	if ready <= 0 {
+		return
+	}
+
+	// never bank more than a tick's worth plus one so a long wait doesn't burst
+	rule.budget += rule.SpawnRate * dt_s
+	if limit := rule.SpawnRate*dt_s + 1; rule.budget > limit {
+		rule.budget = limit
+	}
+
+	// only what's close by matters for density, so the zone's own entities do
+	living := s.livingEntities(rule.EntityType)
+	spawnArea := s.zone.GetSpawnArea(rule.SpawnArea)
+	for ready > 0 && rule.budget >= 1 {
+		rule.budget--
+
+		x, y, found := s.findSpawnPosition(rule, spawnArea, living)
+		if !found {
+			continue
+		}
+
+		job := ""
+		gotchiData := web3.DefaultSubgraphGotchiData
+		if rule.EntityType == "gotchi" {
+			job = pickScenarioJob(s.zone.Rand, rule.Jobs, rule.jobs)
+			gotchiData = s.nextGotchiData()
+		}
+		e, err := s.zone.WorldManager.spawnEntity(s.zone.Rand, rule.EntityType, x, y, gotchiData, job)
+		if errors.Is(err, errFootprintBlocked) {
+			continue
+		}
+		if err != nil {
+			log.Printf("ERROR [%s]: Can't spawn entity type %s: %v", utils.GetFuncName(), rule.EntityType, err)
+			break
+		}
+		applyScenarioStats(e, rule.Stats)
+		applyScenarioSelection(e, rule.SelectionMode, rule.Temperature)
+		living = append(living, e)
+		rule.members = append(rule.members, e.GetUUID())
+		ready--
+		population++
+		rule.consumeRespawn(now)
+	}
+
+	rule.population = population
+}
+
+// nextGotchiData is the default gotchi with a token id of its own, so spawned
+// gotchis can be told apart (and looked up) by token id. The ids aren't real
+// aavegotchi tokens, they're prefixed so they can't clash with one.
+func (s *Spawner) nextGotchiData() web3.SubgraphGotchiData {
+	s.gotchisSpawned++
+	data := web3.DefaultSubgraphGotchiData
+	data.ID = fmt.Sprintf("spawned-%d-%d", s.zone.ID, s.gotchisSpawned)
+	data.Name = fmt.Sprintf("Spawned %d-%d", s.zone.ID, s.gotchisSpawned)
+	data.ModifiedNumericTraits = append([]int(nil), data.ModifiedNumericTraits...)
+	return data
+}
+
+// consumeRespawn drops the earliest due respawn, if there is one
+func (rule *SpawnRule) consumeRespawn(now time.Duration) {
+	for i, at := range rule.respawnAt {
+		if at <= now {
+			rule.respawnAt = append(rule.respawnAt[:i], rule.respawnAt[i+1:]...)
+			return
+		}
+	}
+}
+
+// findSpawnPosition picks a tile in the rule's spawn area (or anywhere in the
+// zone) that has enough space around it and isn't too crowded
+func (s *Spawner) findSpawnPosition(rule *SpawnRule, spawnArea *SpawnArea, living []interfaces.IEntity) (int, int, bool) {
+	z := s.zone
+
+	var x, y int
+	if spawnArea != nil {
+		var ok bool
+		if x, y, ok = spawnArea.GetRandomPosition(z.Rand); !ok {
+			return 0, 0, false
+		}
+	} else {
+		x = z.X + z.Rand.Intn(z.Width)
+		y = z.Y + z.Rand.Intn(z.Height)
+	}
+
+	if rule.MinSpacing > 0 || !z.IsPositionAvailable(x, y) {
+		var found bool
+		x, y, found = z.FindNearbyAvailablePosition(z.Rand, x, y, spawnSearchRadius, rule.MinSpacing)
+		if !found || !z.isPositionWithinZone(x, y) {
+			return 0, 0, false
+		}
+	}
+
+	// nothing spawned walled into a pocket could ever get out (or be got at)
+	if !z.isInOpenRegion(x, y) {
+		return 0, 0, false
+	}
+
+	if rule.MaxDensity > 0 {
+		nearby := 0
+		for _, e := range living {
+			ex, ey := e.GetPosition()
+			if utils.Abs(ex-x) <= rule.DensityRadius && utils.Abs(ey-y) <= rule.DensityRadius {
+				nearby++
+			}
+		}
+		if nearby >= rule.MaxDensity {
+			return 0, 0, false
+		}
+	}
+
+	return x, y, true
+}
+
+// livingEntities returns the zone's entities of a type that aren't dead
+func (s *Spawner) livingEntities(entityType string) []interfaces.IEntity {
+	living := make([]interfaces.IEntity, 0)
+	for _, e := range s.zone.EntitiesByType[entityType] {
+		if !isDead(e) {
+			living = append(living, e)
+		}
+	}
+	return living
+}
+
+func isDead(e interfaces.IEntity) bool {
+	stateful, ok := e.(entitystate.IEntityState)
+	return ok && stateful.GetState() == entitystate.Dead
+}
@@ -0,0 +1,185 @@
+package world
+
+import (
+	"strings"
+	"testing"
+	"thereaalm/entity"
+	"thereaalm/interfaces"
+	"thereaalm/stattypes"
+)
+
+const spawnerScenario = `{
+  "name": "spawner",
+  "zones": [
+    {
+      "zoneId": 42,
+      "populations": [
+        {"type": "gotchi", "count": 20, "jobs": {"farmer": 1},
+         "spawner": {"spawnRate": 1, "respawnDelay_s": 5}}
+      ]
+    }
+  ]
+}`
+
+func newSpawnerTestWorld(t *testing.T) (*WorldManager, *SpawnRule) {
+	t.Helper()
+
+	wm := NewWorldManager(2, 11, testManifestPath, "")
+	scenario, err := ParseScenario("spawner", []byte(spawnerScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wm.ApplyScenario(scenario); err != nil {
+		t.Fatal(err)
+	}
+	rules := wm.Zones[42].(*Zone).Spawner.Rules
+	if len(rules) != 1 {
+		t.Fatalf("got %d spawn rules, want 1", len(rules))
+	}
+	return wm, rules[0]
+}
+
+func livingGotchis(wm *WorldManager) []*entity.Gotchi {
+	var gotchis []*entity.Gotchi
+	for _, z := range wm.Zones {
+		for _, e := range z.(*Zone).GetEntitiesByType("gotchi") {
+			if g := e.(*entity.Gotchi); !isDead(g) {
+				gotchis = append(gotchis, g)
+			}
+		}
+	}
+	return gotchis
+}
+
+// gotchis that wander into the next zone are still the rule's, they mustn't
+// be replaced
+func TestSpawnerFollowsMembersAcrossZones(t *testing.T) {
+	wm, rule := newSpawnerTestWorld(t)
+	wm.StepN(1)
+	before := len(livingGotchis(wm))
+
+	// walk half of them over into zone 43
+	nx, ny := wm.Zones[43].GetPosition()
+	moved := 0
+	for _, e := range wm.Zones[42].(*Zone).GetEntitiesByType("gotchi")[:10] {
+		x, y, found := wm.FindNearbyAvailablePosition(wm.Rand, nx+ZoneTiles/2, ny+ZoneTiles/2, 32, 0)
+		if !found {
+			t.Fatal("no room in zone 43")
+		}
+		e.(interfaces.IMover).StopMoving()
+		e.SetPosition(x, y)
+		moved++
+	}
+	wm.StepN(1)
+	if n := len(wm.Zones[43].(*Zone).GetEntitiesByType("gotchi")); n < moved {
+		t.Fatalf("%d gotchis made it to zone 43, want %d", n, moved)
+	}
+
+	wm.StepN(60)
+	if after := len(livingGotchis(wm)); after != before {
+		t.Fatalf("%d living gotchis after some left the zone, want %d", after, before)
+	}
+	if rule.population != before {
+		t.Fatalf("rule counts %d members, want %d", rule.population, before)
+	}
+}
+
+// dead gotchis are replaced, each with a token id of its own
+func TestSpawnerReplacesDeadWithNewIdentities(t *testing.T) {
+	wm, rule := newSpawnerTestWorld(t)
+	wm.StepN(1)
+	target := len(livingGotchis(wm))
+
+	for _, g := range livingGotchis(wm)[:3] {
+		g.DeltaStat(stattypes.Pulse, -10000)
+	}
+	wm.StepN(1)
+	if got := len(livingGotchis(wm)); got != target-3 {
+		t.Fatalf("%d living gotchis after 3 died, want %d", got, target-3)
+	}
+
+	wm.StepN(20)
+	if got := len(livingGotchis(wm)); got != target {
+		t.Fatalf("%d living gotchis after respawning, want %d", got, target)
+	}
+	if rule.population != target {
+		t.Fatalf("rule counts %d members, want %d", rule.population, target)
+	}
+
+	tokenIDs := make(map[string]bool)
+	for _, g := range livingGotchis(wm) {
+		id := g.GetGotchiID()
+		if !strings.HasPrefix(id, "spawned-") {
+			continue
+		}
+		if tokenIDs[id] {
+			t.Fatalf("token id %s used twice", id)
+		}
+		tokenIDs[id] = true
+		if wm.GetGotchiByTokenID(id) != g {
+			t.Fatalf("token id %s doesn't find its gotchi", id)
+		}
+	}
+	if len(tokenIDs) != 3 {
+		t.Fatalf("%d respawned gotchis with their own token id, want 3", len(tokenIDs))
+	}
+}
+
+const weightedSpawnerScenario = `{
+  "name": "weighted spawner",
+  "zones": [
+    {
+      "zoneId": 42,
+      "populations": [
+        {"type": "gotchi", "count": 30, "jobs": {"farmer": 9, "explorer": 1},
+         "stats": {"maxpulse": 1234, "brn": 99},
+         "spawner": {"spawnRate": 100}}
+      ]
+    }
+  ]
+}`
+
+// respawned gotchis get their population's jobs by weight and its stats, not
+// whatever a fresh gotchi has
+func TestSpawnerRespawnsFollowPopulation(t *testing.T) {
+	wm := NewWorldManager(2, 5, testManifestPath, "")
+	scenario, err := ParseScenario("weighted spawner", []byte(weightedSpawnerScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wm.ApplyScenario(scenario); err != nil {
+		t.Fatal(err)
+	}
+	wm.StepN(1)
+
+	for _, g := range livingGotchis(wm) {
+		g.DeltaStat(stattypes.Pulse, -10000)
+	}
+	wm.StepN(5)
+
+	jobs := make(map[string]int)
+	respawned := 0
+	for _, g := range livingGotchis(wm) {
+		if !strings.HasPrefix(g.GetGotchiID(), "spawned-") {
+			t.Fatalf("gotchi %s survived being killed", g.GetGotchiID())
+		}
+		respawned++
+		jobs[g.Job]++
+		if got := g.GetStat(stattypes.MaxPulse); got != 1234 {
+			t.Fatalf("respawned gotchi has max pulse %v, want 1234", got)
+		}
+		if got := g.GetStat(stattypes.BRN); got != 99 {
+			t.Fatalf("respawned gotchi has brn %v, want 99", got)
+		}
+	}
+
+	if respawned < 20 {
+		t.Fatalf("only %d gotchis respawned", respawned)
+	}
+	if jobs["farmer"]+jobs["explorer"] != respawned {
+		t.Fatalf("respawned with jobs %v, want only farmers and explorers", jobs)
+	}
+	if share := float64(jobs["farmer"]) / float64(respawned); share < 0.7 {
+		t.Fatalf("%.0f%% of respawns are farmers (%v), want about 90%%", 100*share, jobs)
+	}
+}
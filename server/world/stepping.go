@@ -0,0 +1,228 @@
+package world
+
+import (
+	"log"
+	"sync"
+	"thereaalm/calendar"
+	"time"
+)
+
+const (
+	DefaultFixedTimestep = 1 * time.Second
+)
+
+// Run starts the real-time loop. Every tick advances the world by one
+// FixedTimestep of game time, and the SpeedMultiplier only changes how often
+// ticks happen in real time, so a run is the same at any speed.
+func (wm *WorldManager) Run() {
+	log.Printf("World is running with %d workers...", wm.WorkerCount)
+
+	wm.controlMu.Lock()
+	wm.stopLoop = make(chan struct{})
+	wm.loopDone = make(chan struct{})
+	stop, done := wm.stopLoop, wm.loopDone
+	wm.controlMu.Unlock()
+
+	go wm.runLoop(stop, done)
+}
+
+func (wm *WorldManager) runLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	next := time.Now().Add(wm.realTickInterval())
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+
+		if !wm.IsPaused() {
+			wm.StepN(1)
+		}
+
+		// schedule the next tick from the previous deadline so we don't drift,
+		// but don't try to catch up if we've fallen more than a tick behind
+		interval := wm.realTickInterval()
+		next = next.Add(interval)
+		if now := time.Now(); next.Before(now) {
+			next = now.Add(interval)
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// realTickInterval is how much real time passes between ticks of Run()
+func (wm *WorldManager) realTickInterval() time.Duration {
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	return time.Duration(float64(wm.FixedTimestep) / wm.SpeedMultiplier)
+}
+
+// Step advances the world by dt of game time in a single tick and returns the
+// new tick number. It is safe to call from any goroutine, calls are serialised.
+func (wm *WorldManager) Step(dt time.Duration) uint64 {
+	wm.stepMu.Lock()
+	defer wm.stepMu.Unlock()
+
+	if dt <= 0 {
+		log.Println("WARNING: Step dt must be positive, ignoring:", dt)
+		return wm.tick.Load()
+	}
+	if wm.stopped {
+		return wm.tick.Load()
+	}
+
+	// external mutations land first so every zone sees them this tick
+	wm.applyCommands()
+	wm.countSpawnerPopulations()
+
+	wm.GameTime += dt
+	wm.Scheduler.RunDue(wm.GameTime)
+	wm.updateZonesParallel(dt.Seconds())
+
+	// publish before the tick counter moves so a watched zone's snapshot is
+	// never behind GetTick()
+	tick := wm.tick.Load() + 1
+	wm.publishSnapshots(tick)
+	wm.tick.Store(tick)
+
+	return tick
+}
+
+// StepN advances the world by n ticks of FixedTimestep and returns the new tick number
+func (wm *WorldManager) StepN(n int) uint64 {
+	dt := wm.GetFixedTimestep()
+
+	tick := wm.GetTick()
+	for i := 0; i < n; i++ {
+		tick = wm.Step(dt)
+	}
+	return tick
+}
+
+// GetTick returns the number of ticks completed so far
+func (wm *WorldManager) GetTick() uint64 {
+	return wm.tick.Load()
+}
+
+func (wm *WorldManager) updateZonesParallel(dt_s float64) {
+	for _, zones := range wm.zonePhases {
+		wm.updateZonePhase(zones, dt_s)
+
+		// settle cross-zone changes before the next phase reads them
+		wm.flushPendingZoneChanges()
+		wm.migrateEntities()
+	}
+}
+
+// forEachZone runs fn for every zone on the worker pool and waits for them all
+func (wm *WorldManager) forEachZone(zones []*Zone, fn func(zone *Zone)) {
+	var wg sync.WaitGroup
+	for _, zone := range zones {
+		zone := zone
+		wg.Add(1)
+		submitted := wm.pool.Submit(func() {
+			defer wg.Done()
+			fn(zone)
+		})
+		if !submitted {
+			// the pool only closes on shutdown, finish the tick ourselves
+			fn(zone)
+			wg.Done()
+		}
+	}
+	wg.Wait()
+}
+
+// Pause stops Run() from advancing the world until Resume() is called
+func (wm *WorldManager) Pause() {
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	wm.paused = true
+	log.Println("Simulation paused")
+}
+
+// Resume lets Run() advance the world again
+func (wm *WorldManager) Resume() {
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	wm.paused = false
+	log.Println("Simulation resumed")
+}
+
+func (wm *WorldManager) IsPaused() bool {
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	return wm.paused
+}
+
+// SingleStep advances a paused world by exactly one tick. It returns false
+// (and does nothing) if the world isn't paused.
+func (wm *WorldManager) SingleStep() bool {
+	if !wm.IsPaused() {
+		log.Println("WARNING: SingleStep only works while the simulation is paused")
+		return false
+	}
+
+	wm.StepN(1)
+	return true
+}
+
+// SetFixedTimestep sets the game time advanced by each tick (sub-second values are fine)
+func (wm *WorldManager) SetFixedTimestep(dt time.Duration) {
+	if dt <= 0 {
+		log.Println("WARNING: Fixed timestep must be positive, ignoring:", dt)
+		return
+	}
+
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	wm.FixedTimestep = dt
+	log.Printf("Fixed timestep set to %v", dt)
+}
+
+func (wm *WorldManager) GetFixedTimestep() time.Duration {
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	return wm.FixedTimestep
+}
+
+// Time access methods
+func (wm *WorldManager) Now() time.Duration {
+	return wm.GameTime
+}
+
+func (wm *WorldManager) Since(startTime time.Duration) time.Duration {
+	if wm.GameTime < startTime {
+		return 0 // Prevent negative durations
+	}
+	return wm.GameTime - startTime
+}
+
+// GetDate returns the calendar date at the current game time
+func (wm *WorldManager) GetDate() calendar.Date {
+	return wm.Calendar.DateAt(wm.GameTime)
+}
+
+func (wm *WorldManager) SetSimulationSpeed(multiplier float64) {
+	if multiplier <= 0 {
+		log.Println("WARNING: Speed multiplier must be positive, ignoring:", multiplier)
+		return
+	}
+
+	wm.controlMu.Lock()
+	defer wm.controlMu.Unlock()
+
+	wm.SpeedMultiplier = multiplier
+	log.Printf("Simulation speed set to %.2fx", multiplier)
+}
@@ -0,0 +1,293 @@
+package world
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"thereaalm/action/combatactions"
+	"thereaalm/config"
+	"thereaalm/entity/entitystate"
+	"thereaalm/interfaces"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	threatSampleInterval     = 10 * time.Second // Game time between threat history samples
+	threatHistoryLength      = 360              // An hour of samples
+	threatEventHistoryLength = 50
+)
+
+// threatEntityTypes are the entity types that count towards threat
+var threatEntityTypes = []string{"lickquidator", "lickvoid", "altar", "gotchi"}
+
+// ThreatSample is a zone's threat level and what made it up at a point in game time
+type ThreatSample struct {
+	GameTime_s      float64 `json:"gameTime_s"`
+	Level           int     `json:"level"`
+	Enemies         int     `json:"enemies"`
+	LickVoids       int     `json:"lickVoids"`
+	DestroyedAltars int     `json:"destroyedAltars"`
+	RecentDeaths    float64 `json:"recentDeaths"` // Gotchi deaths, fading over time
+}
+
+// ThreatEvent records a threat threshold firing
+type ThreatEvent struct {
+	GameTime_s float64    `json:"gameTime_s"`
+	Event      string     `json:"event"`
+	Level      int        `json:"level"`            // Threshold that was crossed
+	Target     *uuid.UUID `json:"target,omitempty"` // Raided altar
+	Raiders    int        `json:"raiders,omitempty"`
+}
+
+// ThreatReport is what the threat meter needs to draw a zone
+type ThreatReport struct {
+	ZoneID  int            `json:"zoneId"`
+	Current ThreatSample   `json:"current"`
+	History []ThreatSample `json:"history"` // Oldest first
+	Events  []ThreatEvent  `json:"events"`  // Oldest first
+}
+
+// zoneThreat is a zone's threat state. Everything above mu is only touched by
+// the zone's own update, the rest is read by the API.
+type zoneThreat struct {
+	level        float64
+	recentDeaths float64
+	deadGotchis  map[uuid.UUID]bool
+	armed        []bool          // Per threshold, false until threat drops back below it
+	lastFired    []time.Duration // Per threshold, -1 = never
+
+	mu      sync.Mutex
+	current ThreatSample
+	history []ThreatSample
+	events  []ThreatEvent
+}
+
+// halfLifeFactor is how much of something with the given half life is left after dt_s
+func halfLifeFactor(dt_s, halfLife_s float64) float64 {
+	return math.Pow(0.5, dt_s/halfLife_s)
+}
+
+// updateThreat rescores the zone's threat and fires any thresholds it crossed.
+// It runs at the end of the zone's update.
+func (z *Zone) updateThreat(dt_s float64) {
+	cfg := &z.WorldManager.Threat
+	t := &z.threat
+
+	sample := ThreatSample{GameTime_s: z.WorldManager.Now().Seconds()}
+	deadGotchis := make(map[uuid.UUID]bool, len(t.deadGotchis))
+	newDeaths := 0
+	for _, entityType := range threatEntityTypes {
+		for _, e := range z.EntitiesByType[entityType] {
+			state := entitystate.Active
+			if stateful, ok := e.(entitystate.IEntityState); ok {
+				state = stateful.GetState()
+			}
+
+			switch e.GetType() {
+			case "lickquidator":
+				if state != entitystate.Dead {
+					sample.Enemies++
+				}
+			case "lickvoid":
+				if state == entitystate.Active {
+					sample.LickVoids++
+				}
+			case "altar":
+				if state == entitystate.Dead {
+					sample.DestroyedAltars++
+				}
+			case "gotchi":
+				if state == entitystate.Dead {
+					deadGotchis[e.GetUUID()] = true
+					if !t.deadGotchis[e.GetUUID()] {
+						newDeaths++
+					}
+				}
+			}
+		}
+	}
+	t.deadGotchis = deadGotchis
+	t.recentDeaths = t.recentDeaths*halfLifeFactor(dt_s, cfg.DeathHalfLife_s) + float64(newDeaths)
+	sample.RecentDeaths = t.recentDeaths
+
+	score := float64(sample.Enemies)*cfg.EnemyWeight +
+		float64(sample.LickVoids)*cfg.LickVoidWeight +
+		float64(sample.DestroyedAltars)*cfg.DestroyedAltarWeight +
+		t.recentDeaths*cfg.GotchiDeathWeight
+
+	// threat rises straight away but only fades back down
+	target := 100 * (1 - math.Exp(-score/cfg.Scale))
+	if target >= t.level {
+		t.level = target
+	} else {
+		t.level = target + (t.level-target)*halfLifeFactor(dt_s, cfg.DecayHalfLife_s)
+	}
+
+	z.ThreatLevel = int(math.Round(t.level))
+	sample.Level = z.ThreatLevel
+
+	t.mu.Lock()
+	t.current = sample
+	t.mu.Unlock()
+
+	z.checkThreatThresholds(cfg.Thresholds)
+}
+
+func (z *Zone) checkThreatThresholds(thresholds []config.ThreatThreshold) {
+	t := &z.threat
+	if len(t.armed) != len(thresholds) {
+		t.armed = make([]bool, len(thresholds))
+		t.lastFired = make([]time.Duration, len(thresholds))
+		for i := range thresholds {
+			t.armed[i] = true
+			t.lastFired[i] = -1
+		}
+	}
+
+	now := z.WorldManager.Now()
+	for i, threshold := range thresholds {
+		if !t.armed[i] {
+			if z.ThreatLevel < threshold.Level-config.ThreatHysteresis {
+				t.armed[i] = true
+			}
+			continue
+		}
+
+		cooldown := time.Duration(threshold.Cooldown_s * float64(time.Second))
+		if z.ThreatLevel < threshold.Level || (t.lastFired[i] >= 0 && now-t.lastFired[i] < cooldown) {
+			continue
+		}
+
+		t.armed[i] = false
+		t.lastFired[i] = now
+		z.fireThreatEvent(threshold)
+	}
+}
+
+func (z *Zone) fireThreatEvent(threshold config.ThreatThreshold) {
+	event := ThreatEvent{
+		GameTime_s: z.WorldManager.Now().Seconds(),
+		Event:      threshold.Event,
+		Level:      threshold.Level,
+	}
+
+	switch threshold.Event {
+	case config.ThreatEventRaid:
+		altar, raiders := z.startRaid(threshold.RaidSize)
+		if altar != nil {
+			id := altar.GetUUID()
+			event.Target = &id
+			event.Raiders = raiders
+		}
+		log.Printf("Zone %d threat passed %d: %d lickquidators raiding", z.ID, threshold.Level, raiders)
+	default:
+		log.Printf("Zone %d threat passed %d", z.ID, threshold.Level)
+	}
+
+	t := &z.threat
+	t.mu.Lock()
+	t.events = append(t.events, event)
+	if len(t.events) > threatEventHistoryLength {
+		t.events = t.events[len(t.events)-threatEventHistoryLength:]
+	}
+	t.mu.Unlock()
+}
+
+// startRaid sends up to size lickquidators after the active altar nearest to
+// the middle of the zone's lickquidators. It returns the altar (nil if there
+// was nothing to raid) and how many lickquidators went.
+func (z *Zone) startRaid(size int) (interfaces.IEntity, int) {
+	var licks, altars []interfaces.IEntity
+	for _, e := range z.EntitiesByType["lickquidator"] {
+		if stateful, ok := e.(entitystate.IEntityState); ok && stateful.GetState() != entitystate.Dead {
+			licks = append(licks, e)
+		}
+	}
+	for _, e := range z.EntitiesByType["altar"] {
+		if stateful, ok := e.(entitystate.IEntityState); ok && stateful.GetState() != entitystate.Dead {
+			altars = append(altars, e)
+		}
+	}
+	if len(licks) == 0 || len(altars) == 0 {
+		return nil, 0
+	}
+
+	sumX, sumY := 0, 0
+	for _, l := range licks {
+		x, y := l.GetPosition()
+		sumX += x
+		sumY += y
+	}
+	centerX, centerY := sumX/len(licks), sumY/len(licks)
+
+	altar := altars[0]
+	bestDist := -1
+	for _, a := range altars {
+		x, y := a.GetPosition()
+		if d := z.GetDistance(centerX, centerY, x, y); bestDist < 0 || d < bestDist {
+			altar, bestDist = a, d
+		}
+	}
+
+	// the closest lickquidators answer the call
+	altarX, altarY := altar.GetPosition()
+	sort.SliceStable(licks, func(i, j int) bool {
+		ix, iy := licks[i].GetPosition()
+		jx, jy := licks[j].GetPosition()
+		return z.GetDistance(ix, iy, altarX, altarY) < z.GetDistance(jx, jy, altarX, altarY)
+	})
+
+	raiders := 0
+	for _, l := range licks {
+		if raiders >= size {
+			break
+		}
+		planner, ok := l.(interfaces.IActionPlan)
+		if !ok {
+			continue
+		}
+		attack := combatactions.NewAttackAction(l, altar, 0, nil)
+		if !attack.IsValidTarget(altar) {
+			continue
+		}
+		planner.InterruptWith(attack)
+		raiders++
+	}
+
+	return altar, raiders
+}
+
+// sampleThreat adds the current threat to the zone's history, it runs on the
+// zone's scheduler every threatSampleInterval
+func (z *Zone) sampleThreat() {
+	t := &z.threat
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.history = append(t.history, t.current)
+	if len(t.history) > threatHistoryLength {
+		t.history = t.history[len(t.history)-threatHistoryLength:]
+	}
+}
+
+// GetThreatReport returns a zone's current threat, its history and the events it fired
+func (wm *WorldManager) GetThreatReport(zoneID int) (*ThreatReport, error) {
+	zone, ok := wm.GetZoneByID(zoneID).(*Zone)
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+
+	t := &zone.threat
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &ThreatReport{
+		ZoneID:  zone.ID,
+		Current: t.current,
+		History: append([]ThreatSample{}, t.history...),
+		Events:  append([]ThreatEvent{}, t.events...),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package world
+
+import (
+	"testing"
+	"thereaalm/config"
+	"thereaalm/entity"
+	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/utils"
+	"time"
+)
+
+func TestThreatThresholds(t *testing.T) {
+	warnAt := func(level int, cooldown_s float64) config.ThreatThreshold {
+		return config.ThreatThreshold{Level: level, Event: config.ThreatEventWarning, Cooldown_s: cooldown_s}
+	}
+
+	type step struct {
+		at_s  float64
+		level int
+		fired []int // levels of the thresholds that fire
+	}
+	tests := []struct {
+		name       string
+		thresholds []config.ThreatThreshold
+		steps      []step
+	}{
+		{"fires when crossed", []config.ThreatThreshold{warnAt(50, 0)}, []step{
+			{0, 49, nil}, {1, 50, []int{50}},
+		}},
+		{"once while it stays up", []config.ThreatThreshold{warnAt(50, 0)}, []step{
+			{0, 60, []int{50}}, {1, 70, nil}, {2, 50, nil},
+		}},
+		{"re-arms below the hysteresis", []config.ThreatThreshold{warnAt(50, 0)}, []step{
+			{0, 60, []int{50}}, {1, 41, nil}, {2, 60, nil}, {3, 39, nil}, {4, 60, []int{50}},
+		}},
+		{"waits out the cooldown", []config.ThreatThreshold{warnAt(50, 100)}, []step{
+			{0, 60, []int{50}}, {10, 0, nil}, {20, 60, nil}, {99, 60, nil}, {100, 60, []int{50}},
+		}},
+		{"thresholds are separate", []config.ThreatThreshold{warnAt(50, 0), warnAt(75, 0)}, []step{
+			{0, 80, []int{50, 75}}, {1, 60, nil}, {2, 64, nil}, {3, 80, []int{75}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &WorldManager{}
+			zone := &Zone{WorldManager: wm, EntitiesByType: map[string][]interfaces.IEntity{}}
+
+			seen := 0
+			for _, s := range tt.steps {
+				wm.GameTime = time.Duration(s.at_s * float64(time.Second))
+				zone.ThreatLevel = s.level
+				zone.checkThreatThresholds(tt.thresholds)
+
+				events := zone.threat.events[seen:]
+				seen = len(zone.threat.events)
+				if len(events) != len(s.fired) {
+					t.Fatalf("at %gs level %d fired %d events, want %v", s.at_s, s.level, len(events), s.fired)
+				}
+				for i, event := range events {
+					if event.Level != s.fired[i] || event.GameTime_s != s.at_s {
+						t.Fatalf("at %gs fired %+v, want level %d", s.at_s, event, s.fired[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestThreatRaid sends the lickquidators closest to an altar after it when a
+// raid threshold is crossed
+func TestThreatRaid(t *testing.T) {
+	wm := NewWorldManager(1, 8, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+
+	var altar *entity.Altar
+	for y := zone.Y + 30; y < zone.Y+zone.Height-30 && altar == nil; y++ {
+		for x := zone.X + 30; x < zone.X+zone.Width-30 && altar == nil; x++ {
+			if wm.IsAreaAvailable(pathfinding.Rect{Min: pathfinding.Point{X: x - 12, Y: y - 3}, W: 25, H: 7}) {
+				altar = entity.NewAltar(utils.NewUUID(wm.Rand), x, y)
+			}
+		}
+	}
+	if altar == nil {
+		t.Fatal("no room for an altar")
+	}
+	wm.AddEntity(altar)
+	ax, ay := altar.GetPosition()
+
+	near := generateGenericLickquidator(wm, utils.NewUUID(wm.Rand), ax-4, ay)
+	nearer := generateGenericLickquidator(wm, utils.NewUUID(wm.Rand), ax+3, ay)
+	far := generateGenericLickquidator(wm, utils.NewUUID(wm.Rand), ax+12, ay)
+
+	zone.ThreatLevel = 80
+	zone.checkThreatThresholds([]config.ThreatThreshold{{Level: 75, Event: config.ThreatEventRaid, RaidSize: 2}})
+
+	events := zone.threat.events
+	if len(events) != 1 {
+		t.Fatalf("fired %d events, want 1", len(events))
+	}
+	if event := events[0]; event.Event != config.ThreatEventRaid || event.Target == nil ||
+		*event.Target != altar.GetUUID() || event.Raiders != 2 {
+		t.Fatalf("got %+v, want 2 raiding the altar", event)
+	}
+
+	for _, lick := range []*entity.Lickquidator{nearer, near} {
+		if lick.CurrentAction == nil || lick.CurrentAction.GetType() != "attack" || lick.CurrentAction.GetTarget() != altar {
+			t.Fatal("a near lickquidator isn't raiding the altar")
+		}
+	}
+	if far.CurrentAction != nil && far.CurrentAction.GetTarget() == altar {
+		t.Fatal("the far lickquidator joined a raid that was already full")
+	}
+}
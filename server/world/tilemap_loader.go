@@ -38,8 +38,8 @@ type Property struct {
     Value interface{} `json:"value"`
 }
 
-// LoadTilemap loads a Tiled tilemap from a JSON file and creates Impassable entities.
-func LoadTilemap(filePath string, worldManager *WorldManager, zoneID int) ([]*SpawnArea, error) {
+// LoadTilemap loads a Tiled tilemap from a JSON file into a zone's obstacles and spawn areas.
+func LoadTilemap(filePath string, zone *Zone) ([]*SpawnArea, error) {
     // Read the JSON file
     data, err := ioutil.ReadFile(filePath)
     if err != nil {
@@ -53,15 +53,11 @@ func LoadTilemap(filePath string, worldManager *WorldManager, zoneID int) ([]*Sp
     }
 
     // Validate the map dimensions
-    if tiledMap.Width != 512 || tiledMap.Height != 512 {
-        log.Printf("Warning: Tilemap dimensions are %dx%d, expected 512x512", tiledMap.Width, tiledMap.Height)
+    if tiledMap.Width != zone.Width || tiledMap.Height != zone.Height {
+        log.Printf("Warning: Tilemap dimensions are %dx%d, expected %dx%d", tiledMap.Width, tiledMap.Height, zone.Width, zone.Height)
     }
 
-    // Find the zone in the world manager
-    zone := worldManager.Zones[zoneID]
-    if zone == nil {
-        log.Fatalf("Zone %d not found in world manager", zoneID)
-    }
+    zoneID := zone.GetID()
 
     // Collection of spawn areas
     spawnAreas := make([]*SpawnArea, 0)
@@ -136,7 +132,10 @@ func LoadTilemap(filePath string, worldManager *WorldManager, zoneID int) ([]*Sp
                 x := zoneWorldX + i % layer.Width
                 y := zoneWorldY + i / layer.Width
 
-                // Add position to spawn area
+                // Add position to spawn area (maps bigger than the zone get clipped)
+                if !zone.isPositionWithinZone(x, y) {
+                    continue
+                }
                 spawnArea.AddPosition(x, y)
                 count++
             }
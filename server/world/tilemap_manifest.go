@@ -0,0 +1,48 @@
+package world
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// TilemapManifest maps each biome name in config.ZoneMap to a Tiled map file.
+// Map paths are relative to the manifest's own directory. Biomes without a
+// map yet are left out and get procedural terrain.
+type TilemapManifest struct {
+	Biomes map[string]string `json:"biomes"`
+
+	dir string
+}
+
+// LoadTilemapManifest reads a tilemap manifest from a JSON file
+func LoadTilemapManifest(filePath string) (*TilemapManifest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest TilemapManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	manifest.dir = filepath.Dir(filePath)
+
+	return &manifest, nil
+}
+
+// GetMapPath returns the map file for a biome, or false if it has none
+func (m *TilemapManifest) GetMapPath(biome string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+
+	mapFile, ok := m.Biomes[biome]
+	if !ok || mapFile == "" {
+		return "", false
+	}
+	if filepath.IsAbs(mapFile) {
+		return mapFile, true
+	}
+	return filepath.Join(m.dir, mapFile), true
+}
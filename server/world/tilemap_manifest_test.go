@@ -0,0 +1,20 @@
+package world
+
+import (
+	"os"
+	"testing"
+)
+
+// every map the manifest lists has to exist, or its zones warn on startup
+func TestManifestMapsExist(t *testing.T) {
+	manifest, err := LoadTilemapManifest(testManifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for biome := range manifest.Biomes {
+		mapPath, _ := manifest.GetMapPath(biome)
+		if _, err := os.Stat(mapPath); err != nil {
+			t.Errorf("biome %s: %v", biome, err)
+		}
+	}
+}
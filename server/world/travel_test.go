@@ -0,0 +1,109 @@
+package world
+
+import (
+	"testing"
+	"thereaalm/action"
+	"thereaalm/action/combatactions"
+	"thereaalm/action/resourceactions"
+	"thereaalm/entity"
+	"thereaalm/entity/resourceentity"
+	"thereaalm/types"
+	"thereaalm/utils"
+	"thereaalm/web3"
+
+	"github.com/google/uuid"
+)
+
+// Something that can't walk mustn't hop over to a target out of reach, and
+// can still work on one it's standing next to
+func TestNonMoversDontTeleport(t *testing.T) {
+	wm := NewWorldManager(1, 3, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+	zx, zy := zone.GetPosition()
+
+	sx, sy, found := wm.FindNearbyAvailablePosition(wm.Rand, zx+ZoneTiles/2, zy+ZoneTiles/2, 8, 2)
+	if !found {
+		t.Fatal("no room for the lickvoid")
+	}
+	lickvoid := entity.NewLickVoid(utils.NewUUID(wm.Rand), sx, sy)
+	lickvoid.Footprint = types.SingleTile // reach is measured from the anchor
+	wm.AddEntity(lickvoid)
+
+	place := func(x, y int) *resourceentity.KekWoodTree {
+		t.Helper()
+		tx, ty, found := wm.FindNearbyAvailablePosition(wm.Rand, x, y, 3, 0)
+		if !found {
+			t.Fatalf("no room for a tree near %d,%d", x, y)
+		}
+		tree := resourceentity.NewKekWoodTree(utils.NewUUID(wm.Rand), tx, ty)
+		wm.AddEntity(tree)
+		return tree
+	}
+
+	var plan action.ActionPlan
+	far := place(sx+20, sy)
+	plan.InterruptWith(combatactions.NewAttackAction(lickvoid, far, 1, nil))
+	if x, y := lickvoid.GetPosition(); x != sx || y != sy {
+		t.Fatalf("lickvoid moved from %d,%d to %d,%d", sx, sy, x, y)
+	}
+	if plan.CurrentAction != nil {
+		t.Fatal("lickvoid is attacking a tree it can't reach")
+	}
+
+	// right next to it
+	near := resourceentity.NewKekWoodTree(utils.NewUUID(wm.Rand), sx+1, sy)
+	if !wm.IsAreaAvailable(near.GetBounds()) {
+		t.Skip("no room for a tree next to the lickvoid")
+	}
+	wm.AddEntity(near)
+	plan.InterruptWith(combatactions.NewAttackAction(lickvoid, near, 1, nil))
+	if plan.CurrentAction == nil || plan.Travelling {
+		t.Fatal("lickvoid didn't start attacking the tree next to it")
+	}
+}
+
+// A gotchi gives its slot back when it heads for another target, and when it
+// leaves the world
+func TestWorkSlotsFollowTheWorker(t *testing.T) {
+	wm := NewWorldManager(1, 4, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+	zx, zy := zone.GetPosition()
+
+	place := func(x, y int) *resourceentity.KekWoodTree {
+		t.Helper()
+		tx, ty, found := wm.FindNearbyAvailablePosition(wm.Rand, x, y, 6, 1)
+		if !found {
+			t.Fatalf("no room for a tree near %d,%d", x, y)
+		}
+		tree := resourceentity.NewKekWoodTree(utils.NewUUID(wm.Rand), tx, ty)
+		wm.AddEntity(tree)
+		return tree
+	}
+	cx, cy := zx+ZoneTiles/2, zy+ZoneTiles/2
+	first, second := place(cx-4, cy), place(cx+4, cy)
+
+	gx, gy, found := wm.FindNearbyAvailablePosition(wm.Rand, cx, cy, 6, 1)
+	if !found {
+		t.Fatal("no room for the gotchi")
+	}
+	gotchi := generateGenericGotchi(wm, utils.NewUUID(wm.Rand), gx, gy, web3.DefaultSubgraphGotchiData, "farmer")
+	nobody := uuid.UUID{}
+
+	gotchi.InterruptWith(resourceactions.NewChopAction(gotchi, first, 1, nil))
+	if n := first.CountOtherClaims(nobody); n != 1 {
+		t.Fatalf("first tree has %d claims, want 1", n)
+	}
+
+	gotchi.InterruptWith(resourceactions.NewChopAction(gotchi, second, 1, nil))
+	if n := first.CountOtherClaims(nobody); n != 0 {
+		t.Fatalf("first tree still has %d claims after retargeting", n)
+	}
+	if n := second.CountOtherClaims(nobody); n != 1 {
+		t.Fatalf("second tree has %d claims, want 1", n)
+	}
+
+	wm.RemoveEntity(gotchi)
+	if n := second.CountOtherClaims(nobody); n != 0 {
+		t.Fatalf("second tree still has %d claims after the gotchi left", n)
+	}
+}
@@ -0,0 +1,30 @@
+package world
+
+import (
+	"thereaalm/interfaces"
+	"thereaalm/weather"
+
+	"github.com/google/uuid"
+)
+
+// startWeather rolls the zone's first spell of weather from its biome's
+// climate. It needs the zone's Biome set.
+func (z *Zone) startWeather() {
+	z.Weather = weather.Spell{Kind: weather.Clear}
+	z.changeWeather()
+
+	// the world starts mid-spell rather than with every zone's weather ramping in
+	z.Weather.Start -= weather.RampTime
+}
+
+// changeWeather rolls the next spell and schedules the change after it
+func (z *Zone) changeWeather() {
+	climate := weather.ClimateFor(z.Biome)
+	z.Weather = climate.NextSpell(z.Rand, z.WorldManager.Now(), z.Weather.Kind)
+	z.Scheduler.ScheduleAt(z.Weather.End, interfaces.PriorityHigh, uuid.Nil, z.changeWeather)
+}
+
+// GetWeather returns the zone's weather right now
+func (z *Zone) GetWeather() weather.State {
+	return z.Weather.StateAt(z.WorldManager.Now())
+}
@@ -1,20 +1,25 @@
 package world
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"thereaalm/action/combatactions"
+	"thereaalm/calendar"
 	"thereaalm/config"
 	"thereaalm/entity"
 	"thereaalm/entity/resourceentity"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
+	"thereaalm/taskpool"
 	"thereaalm/types"
 	"thereaalm/utils"
 	"thereaalm/web3"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -24,13 +29,36 @@ const (
 type WorldManager struct {
 	Zones          []interfaces.IZone
 	WorkerCount    int
-	SpeedMultiplier float64       // 1.0 = normal, 2.0 = double speed
+	SpeedMultiplier float64       // 1.0 = normal, 2.0 = double speed (real-time pacing only)
 	GameTime        time.Duration // Simulated game time
-	LastUpdate      time.Time     // Real time of last update
-	SpawnAreas     []*SpawnArea  // Spawn areas loaded from tilemap
-}
-
-func NewWorldManager(workerCount int) *WorldManager {
+	FixedTimestep   time.Duration // Game time advanced by each tick of Run()
+	Seed           int64         // Seed every zone and entity random stream is derived from
+	Rand           *rand.Rand    // World-level random stream (setup and spawning)
+	Scheduler      *Scheduler    // World system events, run at the start of every tick
+	Calendar       calendar.Calendar // Turns GameTime into days and seasons
+	Threat         config.ThreatConfig // How zone threat is scored, set before Run()
+
+	tick      atomic.Uint64 // Number of completed ticks
+	stepMu    sync.Mutex    // Serialises Step() calls
+	stopped   bool          // Set by Shutdown(), guarded by stepMu
+	controlMu sync.Mutex    // Guards paused, SpeedMultiplier and FixedTimestep
+	paused    bool
+
+	pool       *taskpool.Pool          // Runs zone updates and snapshot builds
+	entities   *entityIndex            // Every entity in the world by uuid and gotchi token id
+	zoneGrid   [][]interfaces.IZone    // Zones by [gridY][gridX], nil where there's no zone
+	zonePhases [zonePhaseCount][]*Zone // Zones grouped so no two neighbours share a phase
+	inPhase    bool                    // True while a phase's zone workers are running
+
+	commandMu      sync.Mutex       // Guards commandQueue and commandsClosed
+	commandQueue   []*queuedCommand // External mutations waiting for the next tick
+	commandsClosed bool             // Set on shutdown, new commands are rejected
+
+	stopLoop chan struct{} // Closed to stop Run()'s loop after the current tick
+	loopDone chan struct{} // Closed once Run()'s loop has exited
+}
+
+func NewWorldManager(workerCount int, seed int64, tilemapManifestPath, scenarioPath string) *WorldManager {
 	if workerCount <= 0 {
 		workerCount = runtime.NumCPU()
 	}
@@ -39,9 +67,15 @@ func NewWorldManager(workerCount int) *WorldManager {
 		WorkerCount: workerCount,
 		SpeedMultiplier: 1.0,
 		GameTime:        0,
-		LastUpdate:      time.Now(),
-		SpawnAreas:      make([]*SpawnArea, 0),
+		FixedTimestep:   DefaultFixedTimestep,
+		Seed:            seed,
+		Rand:            utils.NewRand(utils.DeriveSeed(seed, -1)),
+		Calendar:        calendar.Default,
+		Threat:          config.DefaultThreatConfig(),
+		pool:            taskpool.NewPool(workerCount),
+		entities:        newEntityIndex(),
 	}
+	manager.Scheduler = NewScheduler(manager.Now)
 
 	// Initialize zones
 	zoneID := 0
@@ -51,6 +85,8 @@ func NewWorldManager(workerCount int) *WorldManager {
 				continue
 			}
 			zone := NewZone(manager, zoneID, ZoneTiles, ZoneTiles, x*ZoneTiles, y*ZoneTiles, 64)
+			zone.Biome = zoneType
+			zone.startWeather()
 			manager.Zones = append(manager.Zones, zone)
 			zoneID++
 		}
@@ -61,371 +97,75 @@ func NewWorldManager(workerCount int) *WorldManager {
 		return manager
 	}
 
-	manager.SetSimulationSpeed(1)
-
-	// Load the tilemap for zone 0
-	spawnAreas, err := LoadTilemap("../shared/tilemaps/maps/yield_fields_2.json", manager, 42)
-	if err != nil {
-		log.Fatalf("Failed to load tilemap: %v", err)
-	}
-	manager.SpawnAreas = spawnAreas
-
-	// load test entities
-	manager.loadTestEntities()
-
-	log.Printf("World initialized with %d active zones and %d spawn areas.", len(manager.Zones), len(manager.SpawnAreas))
-	return manager
-}
-
-func (wm *WorldManager) loadTestEntities() {
-	// use one of our gotchisMap
-	idSlice := []string{"4285", "19005", "21550", "8281", "5401",
-		"13401", "2325", "1699", "22313", "4895",
-		"15434", "19553", "22473", "19450", "5410",
-		"928", "22566"}
-
-	// grab a few gotchis to use
-	gotchisMap, err := web3.FetchGotchisByIDs(idSlice)
-	if err != nil {
-		fmt.Printf("Error fetching gotchis: %v\n", err)
-		return
-	}
-
-	// lets start by placing entities in zone 42 only for now
-	zone := wm.Zones[42]
-	zoneWorldX, zoneWorldY := zone.GetPosition()
+	manager.buildZoneGrid()
+	manager.buildZonePhases()
 
-	log.Println("Zone minimum: ", zoneWorldX, zoneWorldY)
-	log.Println("Zone maximum: ", zoneWorldX+zone.GetWidth(), zoneWorldY+zone.GetHeight())
+	manager.SetSimulationSpeed(1)
 
-	// gotchis
-	for i := 0; i < 400; i++ {
-		var posX, posY int
-		var found bool
+	// Load every zone's tilemap (or generate one)
+	spawnAreaCount := manager.loadZoneTerrain(tilemapManifestPath)
 
-		// Try to find a spawn area for gotchis
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == "gotchi" {
-				spawnArea = sa
-				break
-			}
+	// spawn the starting population
+	if scenarioPath != "" {
+		scenario, err := LoadScenario(scenarioPath)
+		if err != nil {
+			log.Fatalf("Failed to load scenario: %v", err)
 		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Println("No valid position found in gotchi spawn area, falling back to random position")
-			}
-		} else {
-			log.Println("No gotchi spawn area found, falling back to random position")
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Println("No available random position found for gotchi after 10 attempts")
-				continue
-			}
-		}
-
-		if !zone.IsPositionAvailable(posX, posY) {
-			emptyX, emptyY, found := wm.FindNearbyAvailablePosition(posX, posY, 10, 0)
-			if found {
-				posX = emptyX
-				posY = emptyY
-			} else {
-				log.Println("No nearby available position found for gotchi")
-				continue
-			}
-		}
-
-		idIndex := rand.Intn(len(idSlice))
-		gotchiId := idSlice[idIndex]
-
-		jobSlice := []string{"mercenary", "farmer",
-			"minerjack", "builder", "explorer"}
-		jobIndex := rand.Intn(5)
-
-		_, exists := gotchisMap[gotchiId]
-		if !exists {
-			generateGenericGotchi(wm, posX, posY,
-				web3.DefaultSubgraphGotchiData, jobSlice[jobIndex])
-		} else {
-			generateGenericGotchi(wm, posX, posY,
-				gotchisMap[gotchiId], jobSlice[jobIndex])
+		if err := manager.ApplyScenario(scenario); err != nil {
+			log.Fatalf("Failed to apply scenario: %v", err)
 		}
 	}
 
-	// lickvoids
-	for i := 0; i < 100; i++ {
-		var posX, posY int
-		var found bool
-
-		// Try to find a spawn area for lickvoids
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == "lickvoid" {
-				spawnArea = sa
-				break
-			}
-		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Println("No valid position found in lickvoid spawn area, falling back to random position")
-			}
-		} else {
-			log.Println("No lickvoid spawn area found, falling back to random position")
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Println("No available random position found for lickvoid after 10 attempts")
-				continue
-			}
-		}
-
-		if !zone.IsPositionAvailable(posX, posY) {
-			log.Println("Position not available for lickvoid")
-			continue
-		}
+	log.Printf("World initialized with %d active zones and %d spawn areas (seed %d).", len(manager.Zones), spawnAreaCount, seed)
+	return manager
+}
 
-		lickvoid := entity.NewLickVoid(posX, posY)
-		wm.AddEntity(lickvoid)
-		lickvoid.SpawnInterval_s = 5
+// loadZoneTerrain gives every zone the tilemap its biome maps to in the
+// manifest. Zones whose biome isn't listed get procedural terrain, as do ones
+// whose map is missing or broken (with a warning, that's a mistake).
+func (wm *WorldManager) loadZoneTerrain(manifestPath string) int {
+	manifest, err := LoadTilemapManifest(manifestPath)
+	if err != nil {
+		log.Printf("WARNING: Failed to load tilemap manifest %s, all zones will be procedural: %v", manifestPath, err)
 	}
 
-	// lickquidators
-	for i := 0; i < 100; i++ {
-		var posX, posY int
-		var found bool
-
-		// Try to find a spawn area for lickquidators
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == "lickquidator" {
-				spawnArea = sa
-				break
-			}
-		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Println("No valid position found in lickquidator spawn area, falling back to random position")
-			}
-		} else {
-			log.Println("No lickquidator spawn area found, falling back to random position")
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Println("No available random position found for lickquidator after 10 attempts")
-				continue
-			}
-		}
-
-		if !zone.IsPositionAvailable(posX, posY) {
-			log.Println("Position not available for lickquidator")
+	spawnAreaCount := 0
+	proceduralCount := 0
+	for _, z := range wm.Zones {
+		zone, ok := z.(*Zone)
+		if !ok {
 			continue
 		}
 
-		generateGenericLickquidator(wm, posX, posY)
-	}
-
-	// resources
-	for i := 0; i < 800; i++ {
-		// pick rando resource
-		resourceSlice := []string{"fomoberrybush", "kekwoodtree", "alphaslateboulders"}
-		resource := resourceSlice[rand.Intn(3)]
-		var posX, posY int
-		var found bool
-
-		// Try to find a spawn area for resources
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == resource {
-				spawnArea = sa
-				break
-			}
-		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Printf("No valid position found in %s spawn area, falling back to random position", resource)
-			}
-		} else {
-			log.Printf("No %s spawn area found, falling back to random position", resource)
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Printf("No available random position found for %s after 10 attempts", resource)
-				continue
-			}
-		}
-
-		if !zone.IsPositionAvailable(posX, posY) {
-			emptyX, emptyY, found := wm.FindNearbyAvailablePosition(posX, posY, 10, 0)
-			if found {
-				posX = emptyX
-				posY = emptyY
-			} else {
-				log.Printf("No nearby available position found for %s", resource)
+		if mapPath, ok := manifest.GetMapPath(zone.Biome); ok {
+			spawnAreas, err := LoadTilemap(mapPath, zone)
+			if err == nil {
+				zone.SpawnAreas = spawnAreas
+				spawnAreaCount += len(spawnAreas)
 				continue
 			}
+			log.Printf("WARNING: Failed to load tilemap %s for zone %d (%s), using procedural terrain: %v",
+				mapPath, zone.ID, zone.Biome, err)
 		}
 
-		if resource == "fomoberrybush" {
-			wm.AddEntity(resourceentity.NewFomoBerryBush(posX, posY))
-		} else if resource == "kekwoodtree" {
-			wm.AddEntity(resourceentity.NewKekWoodTree(posX, posY))
-		} else {
-			wm.AddEntity(resourceentity.NewAlphaSlateBoulders(posX, posY))
-		}
+		zone.SpawnAreas = GenerateProceduralTerrain(zone)
+		spawnAreaCount += len(zone.SpawnAreas)
+		proceduralCount++
 	}
 
-	// altars
-	for i := 0; i < 100; i++ {
-		var posX, posY int
-		var found bool
-
-		// Try to find a spawn area for altars
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == "altar" {
-				spawnArea = sa
-				break
-			}
-		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Println("No valid position found in altar spawn area, falling back to random position")
-			}
-		} else {
-			log.Println("No altar spawn area found, falling back to random position")
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Println("No available random position found for altar after 10 attempts")
-				continue
-			}
-		}
-
-		if !wm.IsPositionAvailable(posX, posY) {
-			log.Println("Position not available for altar")
-			continue
-		}
-
-		wm.AddEntity(entity.NewAltar(posX, posY))
-	}
-
-	// shops
-	for i := 0; i < 50; i++ {
-		var posX, posY int
-		var found bool
-
-		// Try to find a spawn area for shops
-		var spawnArea *SpawnArea
-		for _, sa := range wm.SpawnAreas {
-			if sa.EntitySpawnType == "shop" {
-				spawnArea = sa
-				break
-			}
-		}
-
-		if spawnArea != nil {
-			posX, posY, found = spawnArea.GetRandomPosition()
-			if !found {
-				log.Println("No valid position found in shop spawn area, falling back to random position")
-			}
-		} else {
-			log.Println("No shop spawn area found, falling back to random position")
-		}
-
-		// Fallback to random position if no spawn area or no valid position
-		if spawnArea == nil || !found {
-			for attempts := 0; attempts < 10; attempts++ {
-				posX = rand.Intn(ZoneTiles) + zoneWorldX
-				posY = rand.Intn(ZoneTiles) + zoneWorldY
-				if zone.IsPositionAvailable(posX, posY) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				log.Println("No available random position found for shop after 10 attempts")
-				continue
-			}
-		}
-
-		if !wm.IsPositionAvailable(posX, posY) {
-			log.Println("Position not available for shop")
-			continue
-		}
-
-		wm.AddEntity(entity.NewShop(posX, posY))
-	}
+	log.Printf("Generated procedural terrain for %d of %d zones", proceduralCount, len(wm.Zones))
+	return spawnAreaCount
 }
 
-func generateGenericLickquidator(wm *WorldManager, x, y int) {
-	lickquidator := entity.NewLickquidator(x, y)
+func generateGenericLickquidator(wm *WorldManager, id uuid.UUID, x, y int) *entity.Lickquidator {
+	lickquidator := entity.NewLickquidator(id, x, y)
 	wm.AddEntity(lickquidator)
 
 	lickquidator.AddActionToPlan(combatactions.NewAttackAction(lickquidator, nil, 0.3,
 		&types.TargetSpec{
 			TargetType: "gotchi",
 			TargetCriterion: "nearest",
+			PerceivedOnly: true, // hunt by sight, gotchis can hide behind rocks and in the dark
 		}))
 	lickquidator.AddActionToPlan(combatactions.NewAttackAction(lickquidator, nil, 0.3,
 		&types.TargetSpec{
@@ -437,12 +177,14 @@ func generateGenericLickquidator(wm *WorldManager, x, y int) {
 			TargetType: "shop",
 			TargetCriterion: "nearest",
 		}))
+
+	return lickquidator
 }
 
-func generateGenericGotchi(wm *WorldManager, x, y int,
-	subgraphData web3.SubgraphGotchiData, job string) {
+func generateGenericGotchi(wm *WorldManager, id uuid.UUID, x, y int,
+	subgraphData web3.SubgraphGotchiData, job string) *entity.Gotchi {
 
-	newGotchi := entity.NewGotchi(x, y, subgraphData)
+	newGotchi := entity.NewGotchi(id, x, y, subgraphData)
 	wm.AddEntity(newGotchi)
 	newGotchi.Job = job
 
@@ -461,13 +203,12 @@ func generateGenericGotchi(wm *WorldManager, x, y int,
 	}
 
 	profile(newGotchi)
+
+	return newGotchi
 }
 
 // generateBerryBushes generates 100 unique berry bushes in a 100x100 area within the specified zone
 func generateBerryBushes(wm *WorldManager, zoneID int, zoneX, zoneY int) {
-	// Seed the random number generator
-	rand.Seed(time.Now().UnixNano())
-
 	// Track occupied positions to avoid duplicates
 	occupied := make(map[[2]int]bool)
 	bushesToGenerate := 400
@@ -475,82 +216,22 @@ func generateBerryBushes(wm *WorldManager, zoneID int, zoneX, zoneY int) {
 
 	for len(occupied) < bushesToGenerate {
 		// Generate random coordinates within 100x100 area
-		x := zoneX + rand.Intn(60) // 0+zoneX to 100+zoneX
-		y := zoneY + rand.Intn(60) // 0+zoneY to 100+zoneY
+		x := zoneX + wm.Rand.Intn(60) // 0+zoneX to 100+zoneX
+		y := zoneY + wm.Rand.Intn(60) // 0+zoneY to 100+zoneY
 
 		// Check if position is already occupied
 		pos := [2]int{x, y}
 		if !occupied[pos] {
 			occupied[pos] = true
-			zone.AddEntity(resourceentity.NewFomoBerryBush(x, y))
+			zone.AddEntity(resourceentity.NewFomoBerryBush(utils.NewUUID(wm.Rand), x, y))
 		}
 	}
 }
 
-func (wm *WorldManager) Run() {
-	log.Printf("World is running with %d workers...", wm.WorkerCount)
-	go wm.updateLoop()
-}
-
-func (wm *WorldManager) updateLoop() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		now := time.Now()
-		dt_s := now.Sub(wm.LastUpdate).Seconds()
-		wm.LastUpdate = now
-		wm.updateZonesParallel(dt_s)
-	}
-}
-
-func (wm *WorldManager) updateZonesParallel(dt_s float64) {
-	scaledDt := dt_s * wm.SpeedMultiplier
-	wm.GameTime += time.Duration(scaledDt * float64(time.Second))
-
-	var wg sync.WaitGroup
-	jobs := make(chan interfaces.IZone, len(wm.Zones))
-
-	for i := 0; i < wm.WorkerCount; i++ {
-		wg.Add(1)
-		go wm.zoneWorker(jobs, dt_s*scaledDt, &wg)
-	}
-
-	for _, zone := range wm.Zones {
-		jobs <- zone
-	}
-	close(jobs)
-
-	wg.Wait()
-}
-
-func (wm *WorldManager) zoneWorker(jobs <-chan interfaces.IZone, dt_s float64, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	for zone := range jobs {
-		zone.Update(dt_s)
-	}
-}
-
-// Time access methods
-func (wm *WorldManager) Now() time.Duration {
-	return wm.GameTime
-}
-
-func (wm *WorldManager) Since(startTime time.Duration) time.Duration {
-	if wm.GameTime < startTime {
-		return 0 // Prevent negative durations
-	}
-	return wm.GameTime - startTime
-}
-
-func (wm *WorldManager) SetSimulationSpeed(multiplier float64) {
-	if multiplier <= 0 {
-		log.Println("WARNING: Speed multiplier must be positive, ignoring:", multiplier)
-		return
-	}
-	wm.SpeedMultiplier = multiplier
-	log.Printf("Simulation speed set to %.2fx", multiplier)
+// GetScheduler returns the world level scheduler. Its events run before any
+// zone updates, entities should use their zone's scheduler instead.
+func (wm *WorldManager) GetScheduler() interfaces.IScheduler {
+	return wm.Scheduler
 }
 
 func (wm *WorldManager) IsPositionAvailable(x, y int) bool {
@@ -563,6 +244,25 @@ func (wm *WorldManager) IsPositionAvailable(x, y int) bool {
 	return zone.IsPositionAvailable(x, y)
 }
 
+// IsAreaAvailable checks every tile of a rect is free, for placing entities
+// bigger than a tile
+func (wm *WorldManager) IsAreaAvailable(area pathfinding.Rect) bool {
+	for y := area.Min.Y; y < area.Min.Y+area.H; y++ {
+		for x := area.Min.X; x < area.Min.X+area.W; x++ {
+			if !wm.IsPositionAvailable(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isInOneZone is true if every tile of area lies in the same active zone
+func (wm *WorldManager) isInOneZone(area pathfinding.Rect) bool {
+	zone := wm.getZoneForPosition(area.Min.X, area.Min.Y)
+	return zone != nil && zone == wm.getZoneForPosition(area.Min.X+area.W-1, area.Min.Y+area.H-1)
+}
+
 func (wm *WorldManager) AddEntity(e interfaces.IEntity) {
 	ex, ey := e.GetPosition()
 
@@ -581,21 +281,46 @@ func (wm *WorldManager) RemoveEntity(e interfaces.IEntity) {
 	}
 }
 
-func (wm *WorldManager) getZoneForPosition(x, y int) interfaces.IZone {
-	var zone interfaces.IZone
+// IsPositionInWorld returns true if the position lies inside any active zone
+func (wm *WorldManager) IsPositionInWorld(x, y int) bool {
+	return wm.getZoneForPosition(x, y) != nil
+}
+
+// buildZoneGrid indexes zones by their cell in the zone map so position
+// lookups don't have to scan every zone
+func (wm *WorldManager) buildZoneGrid() {
+	wm.zoneGrid = nil
 	for _, z := range wm.Zones {
 		zoneWorldX, zoneWorldY := z.GetPosition()
-		if x >= zoneWorldX && y >= zoneWorldY &&
-			x < zoneWorldX+z.GetWidth() && y < zoneWorldY+z.GetHeight() {
-			zone = z
-			break
+		gridX := zoneWorldX / ZoneTiles
+		gridY := zoneWorldY / ZoneTiles
+
+		for len(wm.zoneGrid) <= gridY {
+			wm.zoneGrid = append(wm.zoneGrid, nil)
 		}
+		for len(wm.zoneGrid[gridY]) <= gridX {
+			wm.zoneGrid[gridY] = append(wm.zoneGrid[gridY], nil)
+		}
+		wm.zoneGrid[gridY][gridX] = z
+	}
+}
+
+func (wm *WorldManager) getZoneForPosition(x, y int) interfaces.IZone {
+	if x < 0 || y < 0 {
+		return nil
 	}
 
-	return zone
+	gridX := x / ZoneTiles
+	gridY := y / ZoneTiles
+	if gridY >= len(wm.zoneGrid) || gridX >= len(wm.zoneGrid[gridY]) {
+		return nil
+	}
+	return wm.zoneGrid[gridY][gridX]
 }
 
-func (wm *WorldManager) FindNearbyAvailablePosition(x, y, radius, minimumGap int) (int, int, bool) {
+// FindNearbyAvailablePosition finds a free tile near a position using the
+// caller's random stream, see Zone.FindNearbyAvailablePosition
+func (wm *WorldManager) FindNearbyAvailablePosition(rng *rand.Rand, x, y, radius, minimumGap int) (int, int, bool) {
 	// get zone for position
 	zone := wm.getZoneForPosition(x, y)
 	if zone == nil {
@@ -604,7 +329,7 @@ func (wm *WorldManager) FindNearbyAvailablePosition(x, y, radius, minimumGap int
 	}
 
 	// use the zones utility function
-	emptyX, emptyY, found := zone.FindNearbyAvailablePosition(x, y, radius, minimumGap)
+	emptyX, emptyY, found := zone.FindNearbyAvailablePosition(rng, x, y, radius, minimumGap)
 	if found {
 		return emptyX, emptyY, true
 	}
@@ -0,0 +1,115 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testManifestPath = "../../shared/tilemaps/manifest.json"
+
+// testScenario is a small mixed population in two neighbouring zones, enough
+// for gotchis to fight, gather, build, trade and wander between zones
+const testScenario = `{
+  "name": "test",
+  "zones": [
+    {
+      "zoneId": 42,
+      "populations": [
+        {"type": "gotchi", "count": 40, "jobs": {"mercenary": 1, "farmer": 1, "minerjack": 1, "builder": 1, "explorer": 1},
+         "spawner": {"spawnRate": 0.1, "respawnDelay_s": 30}},
+        {"type": "lickvoid", "count": 6},
+        {"type": "lickquidator", "count": 12, "spawner": {"spawnRate": 0.2, "respawnDelay_s": 20}},
+        {"type": "fomoberrybush", "count": 30, "spawner": {"spawnRate": 0.05, "minSpacing": 1}},
+        {"type": "kekwoodtree", "count": 30},
+        {"type": "alphaslateboulders", "count": 30},
+        {"type": "altar", "count": 6, "stats": {"pulse": 400}},
+        {"type": "shop", "count": 3}
+      ]
+    },
+    {
+      "zoneId": 43,
+      "populations": [
+        {"type": "gotchi", "count": 20, "jobs": {"explorer": 1, "mercenary": 1}},
+        {"type": "lickquidator", "count": 6}
+      ]
+    }
+  ]
+}`
+
+// newTestWorld builds a world from testScenario without touching the network
+func newTestWorld(tb testing.TB, workers int, seed int64) *WorldManager {
+	tb.Helper()
+
+	wm := NewWorldManager(workers, seed, testManifestPath, "")
+	scenario, err := ParseScenario("test", []byte(testScenario))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := wm.ApplyScenario(scenario); err != nil {
+		tb.Fatal(err)
+	}
+	return wm
+}
+
+// worldSnapshot encodes a snapshot of every zone with anything in it.
+// Activity log entries are stamped with the wall clock, so those are blanked.
+func worldSnapshot(tb testing.TB, wm *WorldManager) string {
+	tb.Helper()
+
+	var out strings.Builder
+	for _, z := range wm.Zones {
+		zone := z.(*Zone)
+		if len(zone.Entities) == 0 {
+			continue
+		}
+		data, err := json.Marshal(zone.buildSnapshot(wm.GetTick()))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		fmt.Fprintf(&out, "zone %d: %s\n", zone.ID, data)
+	}
+	return logTimePattern.ReplaceAllString(out.String(), `"LogTime":""`)
+}
+
+func TestSameSeedSameWorld(t *testing.T) {
+	const ticks = 200
+
+	// different worker counts too, zone updates mustn't depend on scheduling
+	a := newTestWorld(t, 1, 42)
+	b := newTestWorld(t, 4, 42)
+
+	start := worldSnapshot(t, a)
+	if start != worldSnapshot(t, b) {
+		t.Fatal("worlds differ straight after spawning")
+	}
+
+	a.StepN(ticks)
+	b.StepN(ticks)
+
+	sa, sb := worldSnapshot(t, a), worldSnapshot(t, b)
+	if sa == start {
+		t.Fatalf("nothing happened in %d ticks", ticks)
+	}
+	if sa != sb {
+		linesA, linesB := strings.Split(sa, "\n"), strings.Split(sb, "\n")
+		for i := range linesA {
+			if i >= len(linesB) || linesA[i] != linesB[i] {
+				t.Fatalf("worlds differ after %d ticks, first in line %d", ticks, i)
+			}
+		}
+		t.Fatalf("worlds differ after %d ticks", ticks)
+	}
+}
+
+func TestDifferentSeedDifferentWorld(t *testing.T) {
+	a := newTestWorld(t, 2, 1)
+	b := newTestWorld(t, 2, 2)
+	if worldSnapshot(t, a) == worldSnapshot(t, b) {
+		t.Fatal("different seeds built the same world")
+	}
+}
+
+var logTimePattern = regexp.MustCompile(`"LogTime":"[^"]*"`)
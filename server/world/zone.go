@@ -4,9 +4,12 @@ package world
 import (
 	// "log"
 	"math/rand"
+	"sort"
+	"sync"
 	"thereaalm/interfaces"
+	"thereaalm/pathfinding"
 	"thereaalm/utils"
-	"time"
+	"thereaalm/weather"
 
 	"github.com/google/uuid"
 )
@@ -14,6 +17,7 @@ import (
 type Zone struct {
     ID       int
     Entities []interfaces.IEntity
+    EntitiesByType map[string][]interfaces.IEntity // Same entities grouped by GetType(), in the same order
     Width    int
     Height   int
     X        int
@@ -22,6 +26,24 @@ type Zone struct {
     WorldManager *WorldManager // Add reference to WorldManager
     ObstacleGrid [][]bool
     ThreatLevel int
+    Biome string // Biome name from config.ZoneMap
+    SpawnAreas []*SpawnArea // Spawn areas from the zone's tilemap or procedural terrain
+    Spawner *Spawner // Keeps populations topped up
+    Scheduler *Scheduler // Events for this zone's entities, run at the start of each update
+    Pathfinder *pathfinding.Pathfinder // Paths over ObstacleGrid and occupied tiles, see pathfinding.go
+    Regions *RegionMap // Which tiles can reach each other, see regions.go
+    Weather weather.Spell // Current weather, rolled from the biome's climate
+    Rand *rand.Rand // zone random stream, only touched by this zone's update (queries from other zones take their own)
+
+    // Updating is true while this zone's phase is running. Adds and removals
+    // from neighbouring zones' workers are deferred until the phase ends.
+    Updating bool
+    pendingMu sync.Mutex
+    pendingAdds []interfaces.IEntity
+    pendingRemovals []interfaces.IEntity
+
+    snapshots zoneSnapshots // Published at the end of each tick for readers
+    threat zoneThreat // Scored at the end of each update, see threat.go
 }
 
 func NewZone(wm *WorldManager, id, width, height, x, y, cellSize int) *Zone {
@@ -31,9 +53,10 @@ func NewZone(wm *WorldManager, id, width, height, x, y, cellSize int) *Zone {
         obstacleGrid[i] = make([]bool, width)
     }
 
-    return &Zone{
+    zone := &Zone{
         ID:       id,
         Entities: []interfaces.IEntity{},
+        EntitiesByType: make(map[string][]interfaces.IEntity),
         Width:    width,
         Height:   height,
         X:        x,
@@ -41,8 +64,15 @@ func NewZone(wm *WorldManager, id, width, height, x, y, cellSize int) *Zone {
         SpatialMap: NewSpatialHash(cellSize),
         WorldManager: wm,
         ObstacleGrid: obstacleGrid,
-        ThreatLevel: 50,
+        Rand: utils.NewRand(utils.DeriveSeed(wm.Seed, int64(id))),
     }
+    zone.Spawner = NewSpawner(zone)
+    zone.Scheduler = NewScheduler(wm.Now)
+    zone.Pathfinder = pathfinding.NewPathfinder(zone.isTileWalkable)
+    zone.Regions = NewRegionMap(x, y, width, height, zone.IsObstacle)
+    zone.Scheduler.ScheduleRepeating(threatSampleInterval, interfaces.PriorityLow, uuid.Nil, zone.sampleThreat)
+
+    return zone
 }
 
 func (z *Zone) GetID() int {
@@ -53,52 +83,180 @@ func (z *Zone) GetPosition() (int, int) {
     return z.X, z.Y
 }
 
+func (z *Zone) GetScheduler() interfaces.IScheduler {
+    return z.Scheduler
+}
+
 func (z *Zone) GetWidth() int { return z.Width }
 func (z *Zone) GetHeight() int { return z.Height }
 
+// GetSpawnArea returns the zone's spawn area for an entity type, or nil if it has none
+func (z *Zone) GetSpawnArea(entitySpawnType string) *SpawnArea {
+    for _, sa := range z.SpawnAreas {
+        if sa.EntitySpawnType == entitySpawnType {
+            return sa
+        }
+    }
+    return nil
+}
+
 func (z *Zone) AddEntity(e interfaces.IEntity) {
+    if z.isDeferringChanges() {
+        // point the entity at us straight away so callers can keep setting it
+        // up, it just won't be registered until the phase ends
+        e.SetZone(z)
+        e.SetWorldManager(z.GetWorldManager())
+
+        z.pendingMu.Lock()
+        z.pendingAdds = append(z.pendingAdds, e)
+        z.pendingMu.Unlock()
+        return
+    }
+
+    z.addEntityNow(e)
+    z.WorldManager.entities.add(e)
+}
+
+// RemoveEntity removes an entity from the zone and updates the spatial hash
+func (z *Zone) RemoveEntity(e interfaces.IEntity) {
+    if z.isDeferringChanges() {
+        z.pendingMu.Lock()
+        z.pendingRemovals = append(z.pendingRemovals, e)
+        z.pendingMu.Unlock()
+        return
+    }
+
+    if z.removeEntityNow(e) {
+        z.forgetEntity(e)
+    }
+}
+
+// forgetEntity cleans up after an entity that has left the world for good
+func (z *Zone) forgetEntity(e interfaces.IEntity) {
+    if planner, ok := e.(interfaces.IActionPlan); ok {
+        planner.DropCurrentAction() // gives back any work slot it booked
+    }
+    z.Scheduler.CancelOwner(e.GetUUID())
+    z.WorldManager.entities.remove(e)
+    e.SetZone(nil)
+}
+
+// isDeferringChanges is true when another zone's worker is touching us mid-phase
+func (z *Zone) isDeferringChanges() bool {
+    return z.WorldManager.inPhase && !z.Updating
+}
+
+func (z *Zone) addEntityNow(e interfaces.IEntity) {
     z.Entities = append(z.Entities, e)
+    z.EntitiesByType[e.GetType()] = append(z.EntitiesByType[e.GetType()], e)
     z.SpatialMap.Insert(e)
+    if blocksRegions(e) {
+        z.Regions.AddCover(e.GetBounds(), 1)
+    }
     e.SetZone(z)
     e.SetWorldManager(z.GetWorldManager())
+
+    // give new entities their own random stream derived from the zone's
+    if e.GetRand() == nil {
+        e.SetRand(utils.NewRand(z.Rand.Int63()))
+    }
 }
 
-// RemoveEntity removes an entity from the zone and updates the spatial hash
-func (z *Zone) RemoveEntity(e interfaces.IEntity) {
+// removeEntityNow unregisters an entity but leaves its zone pointer alone so
+// migration can hand it straight to the next zone
+func (z *Zone) removeEntityNow(e interfaces.IEntity) bool {
     for i, entity := range z.Entities {
         if entity.GetUUID() == e.GetUUID() {
             // Remove from entity slice
             z.Entities = append(z.Entities[:i], z.Entities[i+1:]...)
+            z.removeEntityOfType(e)
             z.SpatialMap.Remove(e) // Remove from spatial hash
+            if blocksRegions(e) {
+                z.Regions.AddCover(e.GetBounds(), -1)
+            }
             // log.Println("Removed entity from zone")
-            return
+            return true
+        }
+    }
+    return false
+}
+
+func (z *Zone) removeEntityOfType(e interfaces.IEntity) {
+    entities := z.EntitiesByType[e.GetType()]
+    for i, entity := range entities {
+        if entity.GetUUID() == e.GetUUID() {
+            entities = append(entities[:i], entities[i+1:]...)
+            break
+        }
+    }
+    if len(entities) == 0 {
+        delete(z.EntitiesByType, e.GetType())
+    } else {
+        z.EntitiesByType[e.GetType()] = entities
+    }
+}
+
+// flushPendingChanges applies deferred removals then adds. Adds are sorted by
+// position so the result doesn't depend on which worker queued first.
+func (z *Zone) flushPendingChanges() {
+    z.pendingMu.Lock()
+    removals := z.pendingRemovals
+    adds := z.pendingAdds
+    z.pendingRemovals = nil
+    z.pendingAdds = nil
+    z.pendingMu.Unlock()
+
+    for _, e := range removals {
+        if z.removeEntityNow(e) {
+            z.forgetEntity(e)
+        }
+    }
+
+    sort.SliceStable(adds, func(i, j int) bool {
+        ix, iy := adds[i].GetPosition()
+        jx, jy := adds[j].GetPosition()
+        if iy != jy {
+            return iy < jy
+        }
+        if ix != jx {
+            return ix < jx
         }
+        return adds[i].GetType() < adds[j].GetType()
+    })
+    for _, e := range adds {
+        z.addEntityNow(e)
+        z.WorldManager.entities.add(e)
     }
 }
 
 // Update processes entity movement and updates spatial hash if needed
 func (z *Zone) Update(dt_s float64) {
-    enemyCount := 0
+    // timers come due before anyone acts
+    z.Scheduler.RunDue(z.WorldManager.Now())
+
+    z.Pathfinder.BeginTick(z.WorldManager.GetTick())
+
+    // iterate a copy as entities can remove themselves (or others) mid-update
+    entities := append([]interfaces.IEntity(nil), z.Entities...)
+    for _, e := range entities {
+        if e.GetZone() != z {
+            continue
+        }
 
-    for _, e := range z.Entities {
         oldX, oldY := e.GetPosition()
         e.Update(dt_s) // Allow entity to update itself
         newX, newY := e.GetPosition()
-        // log.Println(e.GetType())
-        if e.GetType() == "lickquidator" {
-            enemyCount++
-        }
 
         // If entity moved, update spatial hash
-        if oldX != newX || oldY != newY {
+        if (oldX != newX || oldY != newY) && e.GetZone() == z {
             z.SpatialMap.Update(e)
         }
     }
 
-    // log.Println(enemyCount)
-    z.ThreatLevel = int(float64(enemyCount) / 1000 * 100)
-    // z.ThreatLevel = int(enemyCount/1000 * 100)
-    // log.Println(z.ThreatLevel)
+    // top populations back up once everyone has had their turn
+    z.Spawner.Update(dt_s)
+
+    z.updateThreat(dt_s)
 }
 
 func (z *Zone) GetThreatLevel() int {
@@ -106,27 +264,51 @@ func (z *Zone) GetThreatLevel() int {
 }
 
 // checks if a world position is available
+// - positions past our edge are checked against the neighbouring zone, as well as
+//   our own spatial hash which may still hold entities that just walked out
 func (z *Zone) IsPositionAvailable(x, y int) bool {
+    if !z.isPositionWithinZone(x, y) {
+        return z.SpatialMap.IsPositionAvailable(x, y) && z.WorldManager.IsPositionAvailable(x, y)
+    }
     return z.SpatialMap.IsPositionAvailable(x, y) && !z.IsObstacle(x, y)
 }
 
-// FindNearbyEntities finds entities within a specified radius
-func (z *Zone) FindNearbyEntities(zoneX, zoneY, radius int) []interfaces.IEntity {
-    entities := []interfaces.IEntity{}
+// FindEntitiesInRadius finds entities in this zone within radius of a position
+func (z *Zone) FindEntitiesInRadius(x, y, radius int, metric interfaces.DistanceMetric) []interfaces.IEntity {
+    var entities []interfaces.IEntity
+    z.SpatialMap.ForEachInRadius(x, y, radius, metric, func(e interfaces.IEntity) bool {
+        entities = append(entities, e)
+        return true
+    })
+    return entities
+}
 
-    // Iterate over neighboring cells in the spatial hash
-    for dx := -radius; dx <= radius; dx++ {
-        for dy := -radius; dy <= radius; dy++ {
-            nearby := z.SpatialMap.GetEntitiesInCell(zoneX+dx, zoneY+dy)
-            entities = append(entities, nearby...)
-        }
-    }
+// FindEntitiesInRect finds entities in this zone inside a rectangle, edges included
+func (z *Zone) FindEntitiesInRect(minX, minY, maxX, maxY int) []interfaces.IEntity {
+    var entities []interfaces.IEntity
+    z.SpatialMap.ForEachInRect(minX, minY, maxX, maxY, func(e interfaces.IEntity) bool {
+        entities = append(entities, e)
+        return true
+    })
     return entities
 }
 
+// FindNearestEntities finds up to k entities of a type ("" for any) within
+// maxRadius (Manhattan), closest first. match can filter them further.
+func (z *Zone) FindNearestEntities(x, y int, entityType string, k, maxRadius int, match func(e interfaces.IEntity) bool) []interfaces.IEntity {
+    return z.SpatialMap.FindNearest(x, y, k, maxRadius, func(e interfaces.IEntity) bool {
+        if entityType != "" && e.GetType() != entityType {
+            return false
+        }
+        return match == nil || match(e)
+    })
+}
+
 // FindNearbyEmptyTile finds a random empty cell within a given radius,
 // ensuring a minimum gap between the returned cell and any entities.
-func (z *Zone) FindNearbyAvailablePosition(x, y, radius, minGap int) (int, int, bool) {
+// Candidates are shuffled with rng, which has to be the caller's own stream
+// as callers in neighbouring zones may be asking at the same time.
+func (z *Zone) FindNearbyAvailablePosition(rng *rand.Rand, x, y, radius, minGap int) (int, int, bool) {
     var candidates []struct{ dx, dy int }
 
     // Populate candidate positions
@@ -135,8 +317,8 @@ func (z *Zone) FindNearbyAvailablePosition(x, y, radius, minGap int) (int, int,
             for dy := -r; dy <= r; dy++ {
                 nx, ny := x+dx, y+dy
 
-                // Bounds check
-                if nx < z.X || ny < z.Y || nx >= z.X + z.Width || ny >= z.Y + z.Height {
+                // Bounds check (candidates may spill into neighbouring zones)
+                if !z.WorldManager.IsPositionInWorld(nx, ny) {
                     continue
                 }
 
@@ -149,7 +331,7 @@ func (z *Zone) FindNearbyAvailablePosition(x, y, radius, minGap int) (int, int,
         return 0, 0, false
     }
 
-    rand.Shuffle(len(candidates), func(i, j int) {
+    rng.Shuffle(len(candidates), func(i, j int) {
         candidates[i], candidates[j] = candidates[j], candidates[i]
     })
 
@@ -163,7 +345,7 @@ func (z *Zone) FindNearbyAvailablePosition(x, y, radius, minGap int) (int, int,
                 tx, ty := nx+gx, ny+gy
 
                 // Skip out-of-bounds tiles in the gap check
-                if tx < z.X || ty < z.Y || tx >= z.X + z.Width || ty >= z.Y + z.Height {
+                if !z.WorldManager.IsPositionInWorld(tx, ty) {
                     continue
                 }
 
@@ -186,55 +368,23 @@ func (z *Zone) FindNearbyAvailablePosition(x, y, radius, minGap int) (int, int,
 }
 
 
-// TryGetEmptyCellAdjacentToEntity attempts to find an empty adjacent cell to the given entity
-// Returns (x, y, true) if an empty cell is found, (0, 0, false) if no empty cell is available
-func (z *Zone) TryGetEmptyTileNextToTargetEntity(target interfaces.IEntity) (int, int, bool) {
-    // Get entity's current position
-    x, y := target.GetPosition()
-    
-    // Define the four adjacent positions
-    adjacent := [4][2]int{
-        {x - 1, y}, // left
-        {x + 1, y}, // right
-        {x, y - 1}, // up
-        {x, y + 1}, // down
-    }
-    
-    var emptyTiles [][2]int
-    for _, pos := range adjacent {
-        if z.IsPositionAvailable(pos[0], pos[1]) { // Rename this call later
-            emptyTiles = append(emptyTiles, pos)
-        }
-    }
-    
-    if len(emptyTiles) == 0 {
-        return 0, 0, false
-    }
-    
-    r := rand.New(rand.NewSource(time.Now().UnixNano()))
-    chosen := emptyTiles[r.Intn(len(emptyTiles))]
-    return chosen[0], chosen[1], true
-}
-
-// GetEntityByUUID retrieves an entity by its UUID
+// GetEntityByUUID retrieves an entity by its UUID if it's in this zone
 func (z *Zone) GetEntityByUUID(uuid uuid.UUID) interfaces.IEntity {
-    for _, entity := range z.Entities {
-        if entity.GetUUID() == uuid {
-            return entity
-        }
+    entity := z.WorldManager.GetEntityByUUID(uuid)
+    if entity == nil || entity.GetZone() != z {
+        return nil
     }
-    return nil
+    return entity
 }
 
-// GetEntitiesByType retrieves all entities of a specific type
+// GetEntitiesByType retrieves all entities of a specific type. It's a copy so
+// callers can hold onto it while entities come and go.
 func (z *Zone) GetEntitiesByType(entityType string) []interfaces.IEntity {
-    var entities []interfaces.IEntity
-    for _, entity := range z.Entities {
-        if entity.GetType() == entityType {
-            entities = append(entities, entity)
-        }
+    entities := z.EntitiesByType[entityType]
+    if len(entities) == 0 {
+        return nil
     }
-    return entities
+    return append([]interfaces.IEntity(nil), entities...)
 }
 
 func (z *Zone) GetEntities() []interfaces.IEntity {
@@ -255,6 +405,8 @@ func (z *Zone) AddObstacle(x, y int) {
     if z.isPositionWithinZone(x, y) {
         // obstacle grid MUST be in local zone coordinates
         z.ObstacleGrid[y-z.Y][x-z.X] = true
+        z.Pathfinder.Invalidate()
+        z.Regions.Update(pathfinding.PointRect(pathfinding.Point{X: x, Y: y}))
     }
 }
 
@@ -262,6 +414,8 @@ func (z *Zone) RemoveObstacle(x, y int) {
     if z.isPositionWithinZone(x, y) {
         // obstacle grid MUST be in local zone coordinates
         z.ObstacleGrid[y-z.Y][x-z.X] = false
+        z.Pathfinder.Invalidate()
+        z.Regions.Update(pathfinding.PointRect(pathfinding.Point{X: x, Y: y}))
     }
 }
 
@@ -0,0 +1,119 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"thereaalm/entity"
+	"thereaalm/utils"
+	"thereaalm/web3"
+)
+
+// benchPopulations are how many entities the benchmarks fill zone 42 with
+var benchPopulations = []int{100, 1000, 10000}
+
+// benchFillerTypes is what the zone is filled with, including everything the
+// benchmark gotchi might target
+var benchFillerTypes = []string{"gotchi", "lickvoid", "fomoberrybush", "kekwoodtree", "alphaslateboulders", "altar", "shop"}
+
+// newBenchWorld builds a world with one builder gotchi in the middle of zone 42
+// and a target of each type a few tiles away. The rest of the zone is filled
+// with population entities that are all too far away to be targets, so only
+// the zone's size should change between runs, not the work of choosing.
+func newBenchWorld(b *testing.B, population int) (*WorldManager, *Zone, *entity.Gotchi) {
+	b.Helper()
+
+	wm := NewWorldManager(1, 1, testManifestPath, "")
+	zone := wm.Zones[42].(*Zone)
+	zx, zy := zone.GetPosition()
+	cx, cy := zx+ZoneTiles/2, zy+ZoneTiles/2
+
+	gotchi := generateGenericGotchi(wm, utils.NewUUID(wm.Rand), cx, cy, web3.DefaultSubgraphGotchiData, "builder")
+
+	neighbours := []string{"fomoberrybush", "kekwoodtree", "alphaslateboulders", "altar", "shop"}
+	for i, entityType := range neighbours {
+		x, y, found := wm.FindNearbyAvailablePosition(wm.Rand, cx-8+4*i, cy+6, 4, 1)
+		if !found {
+			b.Fatalf("no room for a %s next to the gotchi", entityType)
+		}
+		if _, err := wm.spawnEntity(wm.Rand, entityType, x, y, web3.DefaultSubgraphGotchiData, "farmer"); err != nil {
+			b.Fatalf("can't place a %s next to the gotchi: %v", entityType, err)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(int64(population)))
+	for placed := 0; placed < population; {
+		x, y := zx+rng.Intn(ZoneTiles), zy+rng.Intn(ZoneTiles)
+		if max(utils.Abs(x-cx), utils.Abs(y-cy)) < 64 || !wm.IsPositionAvailable(x, y) {
+			continue
+		}
+		entityType := benchFillerTypes[placed%len(benchFillerTypes)]
+		if _, err := wm.spawnEntity(wm.Rand, entityType, x, y, web3.DefaultSubgraphGotchiData, "farmer"); err != nil {
+			continue
+		}
+		placed++
+	}
+
+	if len(zone.Entities) < population {
+		b.Fatalf("only placed %d of %d entities", len(zone.Entities), population)
+	}
+	return wm, zone, gotchi
+}
+
+// BenchmarkSelectNextAction times a gotchi choosing from scratch, with no
+// targets remembered and nothing cached by the pathfinder
+func BenchmarkSelectNextAction(b *testing.B) {
+	for _, population := range benchPopulations {
+		b.Run(fmt.Sprintf("population=%d", population), func(b *testing.B) {
+			wm, zone, gotchi := newBenchWorld(b, population)
+			plan := &gotchi.ActionPlan
+			searched := 0
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				searched += zone.Pathfinder.TickBudget - zone.Pathfinder.Remaining()
+				plan.DropCurrentAction()
+				for _, a := range plan.Actions {
+					a.SetTarget(nil)
+				}
+				zone.Pathfinder.Invalidate()
+				zone.Pathfinder.BeginTick(wm.GetTick())
+				b.StartTimer()
+
+				plan.SelectNextAction()
+			}
+			b.StopTimer()
+			searched += zone.Pathfinder.TickBudget - zone.Pathfinder.Remaining()
+
+			// the search work mustn't change with population. The time creeps
+			// up a little as the GC has a bigger heap to mark, run with
+			// GOGC=off to see it flat.
+			b.ReportMetric(float64(searched)/float64(b.N), "nodes/op")
+			if plan.CurrentAction == nil {
+				b.Fatal("gotchi didn't choose anything to do")
+			}
+		})
+	}
+}
+
+func BenchmarkGetZoneForPosition(b *testing.B) {
+	for _, population := range benchPopulations {
+		b.Run(fmt.Sprintf("population=%d", population), func(b *testing.B) {
+			wm, _, _ := newBenchWorld(b, population)
+
+			width, height := len(wm.zoneGrid[0])*ZoneTiles, len(wm.zoneGrid)*ZoneTiles
+			rng := rand.New(rand.NewSource(1))
+			positions := make([][2]int, 1024)
+			for i := range positions {
+				positions[i] = [2]int{rng.Intn(width), rng.Intn(height)}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p := positions[i%len(positions)]
+				wm.getZoneForPosition(p[0], p[1])
+			}
+		})
+	}
+}